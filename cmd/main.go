@@ -1,26 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"vk/docs"
+	"vk/internal/config"
 	"vk/internal/server"
+	"vk/internal/storage"
+	"vk/internal/storage/memory"
+	"vk/internal/storage/migrate"
 
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 
-	postgres "vk/internal/storage"
+	"vk/internal/storage/postgres"
+	"vk/internal/storage/sqlite"
 
 	"github.com/gorilla/mux"
 )
 
-const (
-	envLocal = "local"
-	envDev   = "dev"
-	envProd  = "prod"
-)
-
 // @title Your API's Title
 // @version 1.0
 // @description Your API's Description
@@ -31,25 +36,94 @@ const (
 // @license.url https://www.apache.org/licenses/LICENSE-2.0.html
 // @host localhost:8080
 // @BasePath /api/v1
+// @securityDefinitions.basic BasicAuth
 func main() {
-	log := setupLogger(envLocal)
-	log.Debug("Init logger")
+	configPath := flag.String("config", "", "path to the config file, overrides CONFIG_PATH")
+	flag.Parse()
+
+	cfg := config.MustLoad(*configPath)
+
+	log := setupLogger(cfg.Env)
+	// код без логгера запроса пишет через slog.Default
+	slog.SetDefault(log)
+	log.Debug("Init logger", slog.String("env", cfg.Env))
 
-	_, err := postgres.New()
+	storage, err := setupStorage(cfg.StorageDriver, cfg.StoragePath)
 	if err != nil {
-		log.Error("failed to init storage")
+		log.Error("failed to init storage", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
-	log.Info("Init database")
+	log.Info("Init database", slog.String("storage", cfg.StorageDriver))
+
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(log, storage, flag.Args()[1:]); err != nil {
+			log.Error("migration failed", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+
+	if m, ok := storage.(migratable); ok {
+		if err := m.Migrator().Check(context.Background()); err != nil {
+			log.Error("refusing to start, run \"migrate up\" first", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
 
-	router := server.SetupRouter()
+	router := server.SetupRouter(storage, cfg, log)
 
-	InitialSwagger()
+	srv := &http.Server{
+		Addr:         cfg.HTTPServer.Address,
+		Handler:      router,
+		ReadTimeout:  cfg.HTTPServer.Timeout,
+		WriteTimeout: cfg.HTTPServer.Timeout,
+		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
+	}
+	swaggerSrv := InitialSwagger(cfg.HTTPServer.SwaggerAddress)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	serveErrors := make(chan error, 2)
+	serve := func(name string, srv *http.Server) {
+		log.Info("starting server", slog.String("server", name), slog.String("address", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErrors <- fmt.Errorf("%s server: %w", name, err)
+		}
+	}
+	go serve("api", srv)
+	go serve("swagger", swaggerSrv)
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Info("shutting down", slog.Duration("timeout", cfg.HTTPServer.ShutdownTimeout))
+	case err := <-serveErrors:
+		log.Error("server failed", slog.String("error", err.Error()))
+		exitCode = 1
+	}
+	stop()
+
+	// Новые соединения больше не принимаются, активные запросы
+	// получают ShutdownTimeout на завершение.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	for name, s := range map[string]*http.Server{"api": srv, "swagger": swaggerSrv} {
+		if err := s.Shutdown(shutdownCtx); err != nil {
+			log.Error("failed to stop server", slog.String("server", name), slog.String("error", err.Error()))
+			exitCode = 1
+		}
+	}
+	cancel()
+
+	if err := storage.Close(); err != nil {
+		log.Error("failed to close storage", slog.String("error", err.Error()))
+		exitCode = 1
+	}
 
-	http.ListenAndServe(":8080", router)
+	log.Info("server stopped")
+	os.Exit(exitCode)
 }
 
-func InitialSwagger() {
+func InitialSwagger(address string) *http.Server {
 	docs.SwaggerInfo.Schemes = []string{"https", "http"}
 
 	r := mux.NewRouter()
@@ -60,22 +134,88 @@ func InitialSwagger() {
 		httpSwagger.DomID("swagger-ui"),
 	)).Methods(http.MethodGet)
 
-	go http.ListenAndServe(":8081", r) // Assuming :8081 as Swagger port
+	return &http.Server{
+		Addr:    address,
+		Handler: r,
+	}
+}
+
+func setupStorage(driver, storagePath string) (storage.Storage, error) {
+	switch driver {
+	case config.StoragePostgres:
+		return postgres.New(storagePath)
+	case config.StorageSQLite:
+		return sqlite.New(storagePath)
+	case config.StorageMemory:
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
+
+// migratable реализуют хранилища, схема которых задается миграциями.
+type migratable interface {
+	Migrator() *migrate.Migrator
+}
+
+// runMigrate выполняет команду "migrate up|down|status".
+func runMigrate(log *slog.Logger, storage storage.Storage, args []string) error {
+	m, ok := storage.(migratable)
+	if !ok {
+		return errors.New("storage does not use migrations")
+	}
+	migrator := m.Migrator()
+	ctx := context.Background()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			log.Info("migration applied", slog.Int("version", migration.Version), slog.String("name", migration.Name))
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			log.Info("schema is up to date", slog.Int("version", migrator.Latest()))
+		}
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info("migration rolled back", slog.Int("version", migration.Version), slog.String("name", migration.Name))
+	case "status":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info("schema status", slog.Int("version", version), slog.Int("latest", migrator.Latest()))
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+
+	return nil
 }
 
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
 
 	switch env {
-	case envLocal:
+	case config.EnvLocal:
 		log = slog.New(
 			slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
 		)
-	case envDev:
+	case config.EnvDev:
 		log = slog.New(
 			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
 		)
-	case envProd:
+	case config.EnvProd:
 		log = slog.New(
 			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
 		)
@@ -25,6 +25,11 @@ const docTemplate = `{
     "paths": {
         "/actor": {
             "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
                 "description": "Добавление нового актера в базу данных",
                 "consumes": [
                     "application/json"
@@ -49,21 +54,51 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "201": {
-                        "description": "Actor created",
+                        "description": "Созданный актер",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Actor"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/api/v1/actor/{id}"
+                            }
                         }
                     },
                     "400": {
                         "description": "Failed to parse request body",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid field values",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
@@ -71,6 +106,11 @@ const docTemplate = `{
         },
         "/actor/{id}": {
             "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
                 "description": "Получение информации об актере по его идентификатору",
                 "consumes": [
                     "application/json"
@@ -89,36 +129,142 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "films"
+                        ],
+                        "type": "string",
+                        "description": "films - добавить к актеру его фильмы",
+                        "name": "include",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Актер, с include=films - models.ActorWithFilms",
                         "schema": {
                             "$ref": "#/definitions/models.Actor"
                         }
                     },
                     "400": {
-                        "description": "Missing actor ID or invalid actor ID",
+                        "description": "Invalid actor ID or include",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "404": {
                         "description": "Actor not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Замена всех полей актера: отсутствующие в запросе поля очищаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "summary": "Заменить актера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID актера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные, id в теле игнорируется",
+                        "name": "actor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Actor"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Актер после замены",
+                        "schema": {
+                            "$ref": "#/definitions/models.Actor"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid actor ID or failed to parse request body",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Actor not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid field values",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
             },
             "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
                 "description": "Удаление актера из базы данных по его идентификатору",
                 "consumes": [
                     "application/json"
@@ -137,6 +283,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Убрать актера из состава фильмов вместо отказа в удалении",
+                        "name": "cascade",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -147,21 +300,50 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid actor ID",
+                        "description": "Invalid actor ID or cascade",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Actor not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Actor is in the cast of some films, listed in details",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
             },
             "patch": {
-                "description": "Обновление информации об актере в базе данных по его идентификатору",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Изменение отдельных полей актера по JSON Merge Patch (RFC 7396): отсутствующие поля не меняются, null очищает поле.\nОчистить можно sex и birthday, name - нет",
                 "consumes": [
                     "application/json"
                 ],
@@ -171,7 +353,7 @@ const docTemplate = `{
                 "tags": [
                     "actors"
                 ],
-                "summary": "Обновить информацию об актере",
+                "summary": "Изменить актера",
                 "parameters": [
                     {
                         "type": "integer",
@@ -181,32 +363,158 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Информация об обновленном актере",
+                        "description": "Изменяемые поля",
                         "name": "actor",
                         "in": "body",
                         "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ActorPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Актер после изменения",
                         "schema": {
                             "$ref": "#/definitions/models.Actor"
                         }
+                    },
+                    "400": {
+                        "description": "Invalid actor ID or failed to parse request body",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Actor not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid field values",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/actor/{id}/films": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Получение фильмов, в которых снимался актер. По умолчанию фильмы отсортированы по дате выхода по возрастанию, фильмы без даты идут последними",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "summary": "Получить фильмы актера",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID актера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "rating",
+                            "release"
+                        ],
+                        "type": "string",
+                        "default": "release",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Направление сортировки, по умолчанию desc для rating и asc для остальных полей",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из next_cursor или prev_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Actor updated",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Page-models_Film"
                         }
                     },
                     "400": {
-                        "description": "Invalid actor ID or failed to decode request body",
+                        "description": "Invalid actor ID, sort, order, limit or cursor",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Actor not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
@@ -214,6 +522,11 @@ const docTemplate = `{
         },
         "/actors": {
             "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
                 "description": "Получение списка всех актеров из базы данных",
                 "consumes": [
                     "application/json"
@@ -225,20 +538,53 @@ const docTemplate = `{
                     "actors"
                 ],
                 "summary": "Получить список всех актеров",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из next_cursor или prev_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "films"
+                        ],
+                        "type": "string",
+                        "description": "films - добавить к каждому актеру его фильмы",
+                        "name": "include",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Страница актеров, с include=films - models.Page[models.ActorWithFilms]",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Actor"
-                            }
+                            "$ref": "#/definitions/models.Page-models_Actor"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid limit, cursor or include",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
@@ -246,7 +592,12 @@ const docTemplate = `{
         },
         "/film": {
             "post": {
-                "description": "Добавление нового фильма",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Добавление нового фильма. Актеры задаются ID существующего актера (число) или объектом нового актера, который создается вместе с фильмом.\nИмя существующего актера (строка) принимается, только если это разрешено в конфиге, и должно быть однозначным",
                 "consumes": [
                     "application/json"
                 ],
@@ -270,21 +621,51 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "201": {
-                        "description": "Film added successfully",
+                        "description": "Созданный фильм вместе с составом",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.FilmWithActors"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "/api/v1/film/{id}"
+                            }
                         }
                     },
                     "400": {
                         "description": "Failed to parse request body",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid field values, unknown actors or ambiguous actor name",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Failed to add film",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
@@ -292,6 +673,11 @@ const docTemplate = `{
         },
         "/film/{id}": {
             "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
                 "description": "Получение информации о фильме по его идентификатору",
                 "consumes": [
                     "application/json"
@@ -320,27 +706,38 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Missing film ID or invalid film ID",
+                        "description": "Invalid film ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "404": {
                         "description": "Film not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Удаление фильма по его идентификатору",
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Замена всех полей фильма: отсутствующие в запросе поля очищаются. Состав фильма не меняется",
                 "consumes": [
                     "application/json"
                 ],
@@ -350,7 +747,7 @@ const docTemplate = `{
                 "tags": [
                     "films"
                 ],
-                "summary": "Удалить фильм",
+                "summary": "Заменить фильм",
                 "parameters": [
                     {
                         "type": "integer",
@@ -358,31 +755,480 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
+                    },
+                    {
+                        "description": "Новые данные, id в теле игнорируется",
+                        "name": "film",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Film"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Фильм после замены",
+                        "schema": {
+                            "$ref": "#/definitions/models.Film"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid film ID or failed to parse request body",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Film not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid field values",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Удаление фильма по его идентификатору вместе со связями с актерами",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Удалить фильм",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Film deleted successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid film ID",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Film not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete film",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Изменение отдельных полей фильма по JSON Merge Patch (RFC 7396): отсутствующие поля не меняются, null очищает поле.\nОчистить можно description и release, name и rating - нет",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Изменить фильм",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "film",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FilmPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Фильм после изменения",
+                        "schema": {
+                            "$ref": "#/definitions/models.Film"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid film ID or failed to parse request body",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Film not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid field values",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/film/{id}/actors": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Получение актеров фильма по возрастанию их идентификаторов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Получить состав фильма",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из next_cursor или prev_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Page-models_Actor"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid film ID, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Film not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Замена всего состава фильма на актеров с указанными идентификаторами. Пустой список очищает состав",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Заменить состав фильма",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID актеров нового состава",
+                        "name": "cast",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FilmCast"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Состав фильма после изменения",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Actor"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid film ID or failed to parse request body",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Film not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid or unknown actor IDs",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Добавление актеров в состав фильма по их идентификаторам. Актеры, уже входящие в состав, пропускаются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Добавить актеров в состав фильма",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID добавляемых актеров",
+                        "name": "cast",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FilmCast"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Film deleted successfully",
+                        "description": "Состав фильма после изменения",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Actor"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Missing film ID or invalid film ID",
+                        "description": "Invalid film ID or failed to parse request body",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Film not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body is too large",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid or unknown actor IDs",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete film",
+                        "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Обновление информации о фильме по его идентификатору",
+            }
+        },
+        "/film/{id}/actors/{actor_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Удаление актера из состава фильма. Сам актер не удаляется",
                 "consumes": [
                     "application/json"
                 ],
@@ -392,7 +1238,7 @@ const docTemplate = `{
                 "tags": [
                     "films"
                 ],
-                "summary": "Обновить информацию о фильме",
+                "summary": "Убрать актера из состава фильма",
                 "parameters": [
                     {
                         "type": "integer",
@@ -402,32 +1248,51 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Измененные данные фильма",
-                        "name": "film",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Film"
-                        }
+                        "type": "integer",
+                        "description": "ID актера",
+                        "name": "actor_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Film updated successfully",
+                        "description": "Состав фильма после изменения",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Actor"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid film ID or failed to decode request body",
+                        "description": "Invalid film ID or actor ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Film not found or actor is not in its cast",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
-                        "description": "Failed to update film",
+                        "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
@@ -435,7 +1300,12 @@ const docTemplate = `{
         },
         "/film_actors/{id}": {
             "get": {
-                "description": "Получение списка всех фильмов, в которых участвовал актер, по его идентификатору",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Устаревший путь, используйте /film/{id}/actors",
                 "consumes": [
                     "application/json"
                 ],
@@ -445,36 +1315,59 @@ const docTemplate = `{
                 "tags": [
                     "film_actors"
                 ],
-                "summary": "Получить список всех фильмов, в которых участвовал актер",
+                "summary": "Получить состав фильма",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID актера",
+                        "description": "ID фильма",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из next_cursor или prev_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Film"
-                            }
+                            "$ref": "#/definitions/models.Page-models_Actor"
                         }
                     },
                     "400": {
-                        "description": "Invalid actor ID",
+                        "description": "Invalid film ID, limit or cursor",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Film not found",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
@@ -482,7 +1375,12 @@ const docTemplate = `{
         },
         "/films": {
             "get": {
-                "description": "Получение списка всех фильмов",
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Получение списка всех фильмов. По умолчанию фильмы отсортированы по рейтингу по убыванию",
                 "consumes": [
                     "application/json"
                 ],
@@ -493,20 +1391,134 @@ const docTemplate = `{
                     "films"
                 ],
                 "summary": "Получить список всех фильмов",
+                "parameters": [
+                    {
+                        "enum": [
+                            "name",
+                            "rating",
+                            "release"
+                        ],
+                        "type": "string",
+                        "default": "rating",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Направление сортировки, по умолчанию desc для rating и asc для остальных полей",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из next_cursor или prev_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Film"
-                            }
+                            "$ref": "#/definitions/models.Page-models_Film"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort, order, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/films/search": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Поиск фильмов по фрагменту названия или имени актера без учета регистра. Фильмы отсортированы по ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Поиск фильмов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фрагмент названия фильма или имени актера, не короче 2 символов",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Размер страницы, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор из next_cursor или prev_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Page-models_Film"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or too short search query, invalid limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/httperr.Response"
                         }
                     }
                 }
@@ -514,20 +1526,66 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "httperr.Response": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "details": {
+                    "type": "object"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "film 1 not found"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Actor": {
             "type": "object",
             "properties": {
                 "birthday": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date",
+                    "x-nullable": true,
+                    "example": "1970-01-31"
                 },
                 "id": {
-                    "type": "string"
+                    "type": "integer"
                 },
                 "name": {
                     "type": "string"
                 },
                 "sex": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ],
+                    "x-nullable": true
+                }
+            }
+        },
+        "models.ActorPatch": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "type": "string",
+                    "format": "date"
+                },
+                "name": {
                     "type": "string"
+                },
+                "sex": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
                 }
             }
         },
@@ -535,16 +1593,17 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "actors": {
+                    "description": "Актеры фильма: ID существующего актера (число), новый актер (объект)\nили, если это разрешено в конфиге, имя существующего актера (строка)",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "type": "object"
                     }
                 },
                 "description": {
                     "type": "string"
                 },
                 "id": {
-                    "type": "string"
+                    "type": "integer"
                 },
                 "name": {
                     "type": "string"
@@ -553,7 +1612,10 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "release": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date",
+                    "x-nullable": true,
+                    "example": "2001-12-31"
                 }
             }
         },
@@ -564,8 +1626,66 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "release": {
+                    "type": "string",
+                    "format": "date",
+                    "x-nullable": true,
+                    "example": "2001-12-31"
+                }
+            }
+        },
+        "models.FilmCast": {
+            "type": "object",
+            "properties": {
+                "actor_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.FilmPatch": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "release": {
+                    "type": "string",
+                    "format": "date"
+                }
+            }
+        },
+        "models.FilmWithActors": {
+            "type": "object",
+            "properties": {
+                "actors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Actor"
+                    }
+                },
+                "description": {
                     "type": "string"
                 },
+                "id": {
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -573,10 +1693,58 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "release": {
+                    "type": "string",
+                    "format": "date",
+                    "x-nullable": true,
+                    "example": "2001-12-31"
+                }
+            }
+        },
+        "models.Page-models_Actor": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Actor"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Page-models_Film": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Film"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "prev_cursor": {
                     "type": "string"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BasicAuth": {
+            "type": "basic"
+        }
     }
 }`
 
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -8,33 +11,168 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+const (
+	EnvLocal = "local"
+	EnvDev   = "dev"
+	EnvProd  = "prod"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type Config struct {
-	Env         string     `yaml:"env" env-default:"local" env-required:"true"`
-	StoragePath string     `yaml:"storage_path" env-required:"true"`
-	HTTPServer  HTTPServer `yaml:"http_server"`
+	Env           string     `yaml:"env" env:"ENV" env-default:"local"`
+	StorageDriver string     `yaml:"storage_driver" env:"STORAGE_DRIVER" env-default:"postgres"`
+	StoragePath   string     `yaml:"storage_path" env:"STORAGE_PATH"`
+	HTTPServer    HTTPServer `yaml:"http_server"`
+	Users         Users      `yaml:"users" env:"API_USERS"`
+	// AllowActorNames оставляет для совместимости ссылки на актеров
+	// по имени при создании фильма. Без него принимаются только ID.
+	AllowActorNames bool `yaml:"allow_actor_names" env:"ALLOW_ACTOR_NAMES" env-default:"true"`
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address" env-default:"localhost:8080"`
-	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
-	User        string        `yaml:"user" env-required:"true"`
+	Address         string        `yaml:"address" env:"HTTP_SERVER_ADDRESS" env-default:"localhost:8080"`
+	SwaggerAddress  string        `yaml:"swagger_address" env:"HTTP_SERVER_SWAGGER_ADDRESS" env-default:"localhost:8081"`
+	Timeout         time.Duration `yaml:"timeout" env:"HTTP_SERVER_TIMEOUT" env-default:"4s"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"HTTP_SERVER_IDLE_TIMEOUT" env-default:"60s"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SERVER_SHUTDOWN_TIMEOUT" env-default:"10s"`
 }
 
-func MustLoad() *Config {
-	configPath := os.Getenv("CONFIG_PATH")
+// User описывает учетную запись для Basic-аутентификации.
+// Роль user дает доступ только к чтению, admin - ко всем методам API.
+type User struct {
+	Name     string `yaml:"name" json:"name"`
+	Password string `yaml:"password" json:"password"`
+	Role     string `yaml:"role" json:"role"`
+}
+
+// Users - учетные записи API. В переменной окружения API_USERS задаются
+// JSON-массивом, например [{"name":"admin","password":"...","role":"admin"}],
+// и целиком заменяют список из файла.
+type Users []User
+
+// SetValue разбирает значение API_USERS для cleanenv.
+func (u *Users) SetValue(s string) error {
+	var users []User
+	if err := json.Unmarshal([]byte(s), &users); err != nil {
+		return fmt.Errorf("expected JSON array of users: %w", err)
+	}
+
+	*u = users
+	return nil
+}
+
+// MustLoad читает конфиг из configPath, а если он пуст, из файла в CONFIG_PATH.
+// Переменные окружения переопределяют значения из файла.
+func MustLoad(configPath string) *Config {
+	if configPath == "" {
+		configPath = os.Getenv("CONFIG_PATH")
+	}
 	if configPath == "" {
 		log.Fatal("CONFIG_PATH is not set")
 	}
+
+	cfg, err := load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cfg
+}
+
+// load читает и проверяет конфиг из файла configPath с учетом переменных окружения.
+func load(configPath string) (*Config, error) {
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		log.Fatalf("config file does not exist: %s", configPath)
+		return nil, fmt.Errorf("config file does not exist: %s", configPath)
 	}
 
 	var cfg Config
 
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		log.Fatalf("cannot read config: %s", err)
+		return nil, fmt.Errorf("cannot read config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", configPath, err)
+	}
+
+	return &cfg, nil
+}
+
+// Validate проверяет значения, которые cleanenv не умеет проверить сам.
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Env {
+	case EnvLocal, EnvDev, EnvProd:
+	default:
+		errs = append(errs, fmt.Errorf("env: unknown value %q, expected %s, %s or %s", c.Env, EnvLocal, EnvDev, EnvProd))
+	}
+
+	switch c.StorageDriver {
+	case StoragePostgres, StorageSQLite:
+		if c.StoragePath == "" {
+			errs = append(errs, fmt.Errorf("storage_path: required for %s storage", c.StorageDriver))
+		}
+	case StorageMemory:
+	default:
+		errs = append(errs, fmt.Errorf("storage_driver: unknown value %q, expected %s, %s or %s",
+			c.StorageDriver, StoragePostgres, StorageSQLite, StorageMemory))
+	}
+
+	if c.HTTPServer.Address == "" {
+		errs = append(errs, errors.New("http_server.address: must not be empty"))
+	}
+	if c.HTTPServer.SwaggerAddress == "" {
+		errs = append(errs, errors.New("http_server.swagger_address: must not be empty"))
+	}
+	if c.HTTPServer.Timeout <= 0 {
+		errs = append(errs, errors.New("http_server.timeout: must be positive"))
+	}
+	if c.HTTPServer.IdleTimeout <= 0 {
+		errs = append(errs, errors.New("http_server.idle_timeout: must be positive"))
+	}
+	if c.HTTPServer.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("http_server.shutdown_timeout: must be positive"))
+	}
+
+	// Вне локального окружения пароли не должны браться из примеров
+	// в репозитории, поэтому пустой список и пароль, совпадающий с именем,
+	// как у admin/admin, считаются ошибкой.
+	if c.Env != EnvLocal && len(c.Users) == 0 {
+		errs = append(errs, fmt.Errorf("users: required in %s env, set API_USERS", c.Env))
+	}
+
+	names := make(map[string]bool, len(c.Users))
+	for i, user := range c.Users {
+		if user.Name == "" {
+			errs = append(errs, fmt.Errorf("users[%d].name: must not be empty", i))
+		} else if names[user.Name] {
+			errs = append(errs, fmt.Errorf("users[%d].name: duplicate user %q", i, user.Name))
+		}
+		names[user.Name] = true
+
+		if user.Password == "" {
+			errs = append(errs, fmt.Errorf("users[%d].password: must not be empty", i))
+		} else if c.Env != EnvLocal && user.Password == user.Name {
+			errs = append(errs, fmt.Errorf("users[%d].password: must not equal the user name in %s env", i, c.Env))
+		}
+
+		switch user.Role {
+		case RoleUser, RoleAdmin:
+		default:
+			errs = append(errs, fmt.Errorf("users[%d].role: unknown value %q, expected %s or %s", i, user.Role, RoleUser, RoleAdmin))
+		}
 	}
 
-	return &cfg
+	return errors.Join(errs...)
 }
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig возвращает конфиг, который проходит Validate.
+func validConfig() Config {
+	return Config{
+		Env:           EnvLocal,
+		StorageDriver: StorageMemory,
+		HTTPServer: HTTPServer{
+			Address:         "localhost:8080",
+			SwaggerAddress:  "localhost:8081",
+			Timeout:         4 * time.Second,
+			IdleTimeout:     time.Minute,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Users: Users{
+			{Name: "admin", Password: "admin", Role: RoleAdmin},
+			{Name: "user", Password: "user", Role: RoleUser},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr []string
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{name: "sqlite with path", modify: func(c *Config) { c.StorageDriver, c.StoragePath = StorageSQLite, "vk.db" }},
+		{name: "unknown env", modify: func(c *Config) { c.Env = "staging" }, wantErr: []string{`env: unknown value "staging"`}},
+		{name: "unknown driver", modify: func(c *Config) { c.StorageDriver = "mysql" }, wantErr: []string{`storage_driver: unknown value "mysql"`}},
+		{name: "sqlite without path", modify: func(c *Config) { c.StorageDriver = StorageSQLite }, wantErr: []string{"storage_path: required for sqlite"}},
+		{name: "postgres without path", modify: func(c *Config) { c.StorageDriver = StoragePostgres }, wantErr: []string{"storage_path: required for postgres"}},
+		{name: "empty address", modify: func(c *Config) { c.HTTPServer.Address = "" }, wantErr: []string{"http_server.address: must not be empty"}},
+		{name: "zero timeout", modify: func(c *Config) { c.HTTPServer.Timeout = 0 }, wantErr: []string{"http_server.timeout: must be positive"}},
+		{name: "negative idle timeout", modify: func(c *Config) { c.HTTPServer.IdleTimeout = -time.Second }, wantErr: []string{"http_server.idle_timeout: must be positive"}},
+		{name: "zero shutdown timeout", modify: func(c *Config) { c.HTTPServer.ShutdownTimeout = 0 }, wantErr: []string{"http_server.shutdown_timeout: must be positive"}},
+		{name: "duplicate users", modify: func(c *Config) { c.Users[1].Name = "admin" }, wantErr: []string{`users[1].name: duplicate user "admin"`}},
+		{name: "unknown role", modify: func(c *Config) { c.Users[0].Role = "root" }, wantErr: []string{`users[0].role: unknown value "root"`}},
+		{name: "empty password", modify: func(c *Config) { c.Users[1].Password = "" }, wantErr: []string{"users[1].password: must not be empty"}},
+		{name: "no users in prod", modify: func(c *Config) { c.Env, c.Users = EnvProd, nil }, wantErr: []string{"users: required in prod env"}},
+		{
+			name:    "example passwords in dev",
+			modify:  func(c *Config) { c.Env = EnvDev },
+			wantErr: []string{"users[0].password: must not equal the user name", "users[1].password: must not equal the user name"},
+		},
+		{
+			name: "all errors reported",
+			modify: func(c *Config) {
+				c.StorageDriver = "mysql"
+				c.HTTPServer.Timeout = 0
+			},
+			wantErr: []string{"storage_driver: unknown value", "http_server.timeout: must be positive"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() error = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+const testConfig = `
+env: "local"
+storage_driver: "sqlite"
+storage_path: "vk.db"
+http_server:
+  address: "localhost:8080"
+  swagger_address: "localhost:8081"
+  timeout: 4s
+  idle_timeout: 60s
+  shutdown_timeout: 10s
+users:
+  - name: "admin"
+    password: "admin"
+    role: "admin"
+`
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(t *testing.T, cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "file",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.StorageDriver != StorageSQLite || cfg.StoragePath != "vk.db" || len(cfg.Users) != 1 {
+					t.Errorf("config = %+v", cfg)
+				}
+			},
+		},
+		{
+			name: "env overrides file",
+			env: map[string]string{
+				"STORAGE_DRIVER":      StorageMemory,
+				"HTTP_SERVER_TIMEOUT": "15s",
+				"API_USERS":           `[{"name":"ops","password":"s3cret","role":"admin"},{"name":"viewer","password":"pa55","role":"user"}]`,
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.StorageDriver != StorageMemory || cfg.HTTPServer.Timeout != 15*time.Second {
+					t.Errorf("config = %+v", cfg)
+				}
+				// API_USERS заменяет список из файла целиком
+				want := Users{{Name: "ops", Password: "s3cret", Role: RoleAdmin}, {Name: "viewer", Password: "pa55", Role: RoleUser}}
+				if len(cfg.Users) != len(want) || cfg.Users[0] != want[0] || cfg.Users[1] != want[1] {
+					t.Errorf("users = %+v, want %+v", cfg.Users, want)
+				}
+			},
+		},
+		{name: "bad API_USERS JSON", env: map[string]string{"API_USERS": `{"name":"ops"}`}, wantErr: "expected JSON array of users"},
+		{name: "bad timeout", env: map[string]string{"HTTP_SERVER_TIMEOUT": "soon"}, wantErr: "cannot read config"},
+		{name: "invalid after override", env: map[string]string{"ENV": EnvProd}, wantErr: "users[0].password: must not equal the user name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("load() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("load() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+
+	if _, err := load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("load() of missing file error = nil")
+	}
+}
@@ -0,0 +1,24 @@
+// Package logger передает логгер запроса через context, чтобы обработчики
+// и хранилища писали в лог вместе с идентификатором запроса.
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type ctxKey struct{}
+
+// WithContext возвращает контекст с логгером log.
+func WithContext(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, log)
+}
+
+// FromContext возвращает логгер из контекста, а если его там нет - slog.Default().
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return log
+	}
+
+	return slog.Default()
+}
@@ -1,8 +1,79 @@
 package models
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Sex - пол актера. Пустое значение означает, что пол неизвестен,
+// в JSON оно записывается как null. Допустимость значения из запроса
+// проверяет валидация.
+type Sex string
+
+const (
+	SexMale   Sex = "male"
+	SexFemale Sex = "female"
+)
+
+// Valid сообщает, что s - один из известных полов или пустое значение.
+func (s Sex) Valid() bool {
+	switch s {
+	case "", SexMale, SexFemale:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s Sex) MarshalJSON() ([]byte, error) {
+	if s == "" {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(string(s))
+}
+
+// Scan читает пол из базы, NULL дает пустое значение.
+func (s *Sex) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*s = ""
+	case string:
+		*s = Sex(v)
+	case []byte:
+		*s = Sex(v)
+	default:
+		return fmt.Errorf("cannot scan %T into models.Sex", src)
+	}
+	if !s.Valid() {
+		return fmt.Errorf("scan models.Sex: unknown value %q", string(*s))
+	}
+
+	return nil
+}
+
+// Value записывает пустой пол как NULL.
+func (s Sex) Value() (driver.Value, error) {
+	if !s.Valid() {
+		return nil, fmt.Errorf("unknown sex %q", string(s))
+	}
+	if s == "" {
+		return nil, nil
+	}
+
+	return string(s), nil
+}
+
 type Actor struct {
-	ID       string `json:"id"`
+	ID       int64  `json:"id"`
 	Name     string `json:"name"`
-	Sex      string `json:"sex"`
-	Birthday string `json:"birthday"`
+	Sex      Sex    `json:"sex" swaggertype:"string" enums:"male,female" extensions:"x-nullable"`
+	Birthday Date   `json:"birthday" swaggertype:"string" format:"date" example:"1970-01-31" extensions:"x-nullable"`
+}
+
+// ActorWithFilms - актер вместе с фильмами, в которых он снимался.
+type ActorWithFilms struct {
+	Actor
+	Films []Film `json:"films"`
 }
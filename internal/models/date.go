@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Date - календарная дата без времени. В JSON это строка "2006-01-02",
+// нулевая дата означает, что дата неизвестна, и записывается как null.
+type Date struct {
+	time.Time
+	// invalid - значение из JSON, которое не разобралось как дата. Его
+	// отклоняет валидация, чтобы ошибка указывала на поле запроса.
+	invalid string
+}
+
+// NewDate возвращает дату year-month-day.
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate разбирает дату в формате YYYY-MM-DD.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return Date{}, err
+	}
+
+	return Date{Time: t}, nil
+}
+
+// Valid сообщает, что дата пришла в формате YYYY-MM-DD или не задана.
+func (d Date) Valid() bool {
+	return d.invalid == ""
+}
+
+// String возвращает дату в формате YYYY-MM-DD, для нулевой даты - пустую строку.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+
+	return d.Format(time.DateOnly)
+}
+
+// Compare сравнивает даты как time.Time.Compare.
+func (d Date) Compare(other Date) int {
+	return d.Time.Compare(other.Time)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON принимает null и строку в формате YYYY-MM-DD. Любое
+// другое значение запоминается, и дата становится не Valid.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		*d = Date{invalid: string(data)}
+		return nil
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		*d = Date{invalid: string(data)}
+		return nil
+	}
+
+	*d = parsed
+	return nil
+}
+
+// Scan читает дату из базы: NULL дает нулевую дату, строки разбираются
+// как YYYY-MM-DD.
+func (d *Date) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Date{}
+		return nil
+	case time.Time:
+		*d = NewDate(v.Date())
+		return nil
+	case string:
+		return d.scanString(v)
+	case []byte:
+		return d.scanString(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into models.Date", src)
+	}
+}
+
+func (d *Date) scanString(s string) error {
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return fmt.Errorf("scan models.Date: %w", err)
+	}
+
+	*d = parsed
+	return nil
+}
+
+// Value записывает дату строкой YYYY-MM-DD, нулевую дату - как NULL.
+func (d Date) Value() (driver.Value, error) {
+	if !d.Valid() {
+		return nil, fmt.Errorf("invalid date %s", d.invalid)
+	}
+	if d.IsZero() {
+		return nil, nil
+	}
+
+	return d.String(), nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDateJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		want      Date
+		wantValid bool
+		wantJSON  string
+	}{
+		{name: "date", body: `"1999-03-31"`, want: NewDate(1999, 3, 31), wantValid: true, wantJSON: `"1999-03-31"`},
+		{name: "null", body: `null`, want: Date{}, wantValid: true, wantJSON: `null`},
+		{name: "wrong format", body: `"31.03.1999"`, wantValid: false},
+		{name: "not a string", body: `19990331`, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Date
+			if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got.Valid() != tt.wantValid {
+				t.Fatalf("Valid() = %v, want %v", got.Valid(), tt.wantValid)
+			}
+			if !tt.wantValid {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal() = %v, want %v", got, tt.want)
+			}
+
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("Marshal() = %s, want %s", data, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestDateScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    Date
+		wantErr bool
+	}{
+		{name: "null", src: nil, want: Date{}},
+		{name: "time", src: time.Date(1999, 3, 31, 15, 4, 5, 0, time.UTC), want: NewDate(1999, 3, 31)},
+		{name: "string", src: "1999-03-31", want: NewDate(1999, 3, 31)},
+		{name: "bytes", src: []byte("1999-03-31"), want: NewDate(1999, 3, 31)},
+		{name: "bad string", src: "yesterday", wantErr: true},
+		{name: "number", src: int64(1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Date
+			err := got.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateValue(t *testing.T) {
+	value, err := NewDate(1999, 3, 31).Value()
+	if err != nil || value != "1999-03-31" {
+		t.Errorf("Value() = %v, %v, want 1999-03-31", value, err)
+	}
+
+	value, err = Date{}.Value()
+	if err != nil || value != nil {
+		t.Errorf("zero Value() = %v, %v, want nil", value, err)
+	}
+
+	var invalid Date
+	if err := json.Unmarshal([]byte(`"tomorrow"`), &invalid); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if _, err := invalid.Value(); err == nil {
+		t.Error("invalid Value() error = nil")
+	}
+}
@@ -1,14 +1,63 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
 type Film struct {
-	ID          string `json:"id"`
+	ID          int64  `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Rating      int    `json:"rating"`
-	Release     string `json:"release"`
+	Release     Date   `json:"release" swaggertype:"string" format:"date" example:"2001-12-31" extensions:"x-nullable"`
 }
 
 type CreateFilm struct {
 	Film
-	Actors []string `json:"actors"`
+	// Актеры фильма: ID существующего актера (число), новый актер (объект)
+	// или, если это разрешено в конфиге, имя существующего актера (строка)
+	Actors []ActorRef `json:"actors" swaggertype:"array,object"`
+}
+
+// FilmWithActors - фильм вместе с его составом.
+type FilmWithActors struct {
+	Film
+	Actors []Actor `json:"actors"`
+}
+
+// ActorRef ссылается на актера в составе нового фильма. Заполнено ровно
+// одно поле: ID существующего актера, его имя или новый актер.
+type ActorRef struct {
+	ID   int64
+	Name string
+	New  *Actor
+}
+
+var errActorRef = errors.New("actor must be an ID, a name or a new actor object")
+
+func (r *ActorRef) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return errActorRef
+	}
+
+	switch data[0] {
+	case '"':
+		return json.Unmarshal(data, &r.Name)
+	case '{':
+		r.New = &Actor{}
+		return json.Unmarshal(data, r.New)
+	default:
+		if err := json.Unmarshal(data, &r.ID); err != nil {
+			return errActorRef
+		}
+		return nil
+	}
+}
+
+// FilmCast - актеры, которых добавляют в состав фильма или которыми его заменяют.
+type FilmCast struct {
+	ActorIDs []int64 `json:"actor_ids"`
 }
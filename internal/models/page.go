@@ -0,0 +1,10 @@
+package models
+
+// Page - страница списка. NextCursor и PrevCursor пусты, если страниц
+// в этом направлении больше нет, Total - число записей во всем списке.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      int    `json:"total"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "encoding/json"
+
+// Field - поле JSON Merge Patch (RFC 7396). Set означает, что поле есть
+// в патче, Null - что его значение null, то есть поле нужно очистить.
+// При null Value остается нулевым значением T, поэтому хранилища просто
+// записывают Value: пустая строка, нулевая Date и пустой Sex означают
+// незаданное значение, а Date и Sex попадают в базу как NULL.
+type Field[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// Of возвращает поле, заданное значением v.
+func Of[T any](v T) Field[T] {
+	return Field[T]{Set: true, Value: v}
+}
+
+// UnmarshalJSON вызывается только для полей, которые есть в патче.
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+
+	return json.Unmarshal(data, &f.Value)
+}
+
+// FilmPatch - изменения фильма. null очищает description и release,
+// для name и rating null недопустим.
+type FilmPatch struct {
+	Name        Field[string] `json:"name" swaggertype:"string"`
+	Description Field[string] `json:"description" swaggertype:"string"`
+	Rating      Field[int]    `json:"rating" swaggertype:"integer"`
+	Release     Field[Date]   `json:"release" swaggertype:"string" format:"date"`
+}
+
+// ActorPatch - изменения актера. null очищает sex и birthday,
+// для name null недопустим.
+type ActorPatch struct {
+	Name     Field[string] `json:"name" swaggertype:"string"`
+	Sex      Field[Sex]    `json:"sex" swaggertype:"string" enums:"male,female"`
+	Birthday Field[Date]   `json:"birthday" swaggertype:"string" format:"date"`
+}
+
+// Patch возвращает патч, заменяющий все поля фильма на значения из f.
+func (f Film) Patch() FilmPatch {
+	return FilmPatch{
+		Name:        Of(f.Name),
+		Description: Of(f.Description),
+		Rating:      Of(f.Rating),
+		Release:     Of(f.Release),
+	}
+}
+
+// Patch возвращает патч, заменяющий все поля актера на значения из a.
+func (a Actor) Patch() ActorPatch {
+	return ActorPatch{
+		Name:     Of(a.Name),
+		Sex:      Of(a.Sex),
+		Birthday: Of(a.Birthday),
+	}
+}
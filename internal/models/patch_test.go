@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFilmPatchUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want FilmPatch
+	}{
+		{
+			name: "empty",
+			body: `{}`,
+			want: FilmPatch{},
+		},
+		{
+			name: "values",
+			body: `{"name":"Matrix","rating":9}`,
+			want: FilmPatch{Name: Of("Matrix"), Rating: Of(9)},
+		},
+		{
+			name: "null clears",
+			body: `{"description":null,"release":null}`,
+			want: FilmPatch{
+				Description: Field[string]{Set: true, Null: true},
+				Release:     Field[Date]{Set: true, Null: true},
+			},
+		},
+		{
+			name: "zero value is not null",
+			body: `{"description":"","rating":0}`,
+			want: FilmPatch{Description: Of(""), Rating: Of(0)},
+		},
+		{
+			name: "date",
+			body: `{"release":"1999-03-31"}`,
+			want: FilmPatch{Release: Of(NewDate(1999, 3, 31))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got FilmPatch
+			if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldUnmarshalTypeError(t *testing.T) {
+	var patch ActorPatch
+	err := json.Unmarshal([]byte(`{"name":5}`), &patch)
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Unmarshal() error = %v, want *json.UnmarshalTypeError", err)
+	}
+}
@@ -0,0 +1,18 @@
+// Package requestid передает идентификатор запроса через context, чтобы
+// его видели и middleware, и ответы с ошибками.
+package requestid
+
+import "context"
+
+type ctxKey struct{}
+
+// WithContext возвращает контекст с идентификатором запроса id.
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext возвращает идентификатор запроса или "", если его нет.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
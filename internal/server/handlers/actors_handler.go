@@ -1,66 +1,59 @@
 package handlers
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"net/http"
 	"strconv"
-	"strings"
 	"vk/internal/models"
-	postgres "vk/internal/storage"
+	"vk/internal/server/httperr"
+	"vk/internal/storage"
+	"vk/internal/validation"
 )
 
-func ActorHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		FindActor(w, r)
-	case http.MethodDelete:
-		DeleteActor(w, r)
-	case http.MethodPatch:
-		UpdateActor(w, r)
-	}
-}
-
 // @Summary Получить информацию об актере по ID
 // @Description Получение информации об актере по его идентификатору
 // @Tags actors
 // @Accept json
 // @Produce json
 // @Param id path integer true "ID актера"
-// @Success 200 {object} models.Actor
-// @Failure 400 {string} string "Missing actor ID or invalid actor ID"
-// @Failure 404 {string} string "Actor not found"
-// @Failure 500 {string} string "Internal server error"
+// @Param include query string false "films - добавить к актеру его фильмы" Enums(films)
+// @Success 200 {object} models.Actor "Актер, с include=films - models.ActorWithFilms"
+// @Failure 400 {object} httperr.Response "Invalid actor ID or include"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 404 {object} httperr.Response "Actor not found"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
 // @Router /actor/{id} [get]
-func FindActor(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
-	id_str := parts[len(parts)-1]
-
-	if id_str == "" {
-		http.Error(w, "Missing film ID", http.StatusBadRequest)
+func (h *Handler) FindActor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "actor")
+	if err != nil {
+		httperr.Write(w, r, err)
 		return
 	}
 
-	id, err := strconv.Atoi(id_str)
+	withFilms, err := includeFilms(r)
 	if err != nil {
-		http.Error(w, "Invalid film ID", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	film, err := postgres.FindActor(id)
+	actor, err := h.storage.FindActor(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to find actor: %v", err), http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	filmsJSON, err := json.Marshal(film)
-	if err != nil {
-		http.Error(w, "Failed to marshal actor", http.StatusInternalServerError)
+	if withFilms {
+		actors, err := h.actorsWithFilms(r.Context(), []models.Actor{actor})
+		if err != nil {
+			httperr.Write(w, r, err)
+			return
+		}
+		writeJSON(w, r, actors[0])
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(filmsJSON)
+	writeJSON(w, r, actor)
 }
 
 // @Summary Добавить нового актера
@@ -69,25 +62,36 @@ func FindActor(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param actor body models.Actor true "Информация о новом актере"
-// @Success 201 {string} string "Actor created"
-// @Failure 400 {string} string "Failed to parse request body"
-// @Failure 500 {string} string "Internal server error"
+// @Success 201 {object} models.Actor "Созданный актер"
+// @Header 201 {string} Location "/api/v1/actor/{id}"
+// @Failure 400 {object} httperr.Response "Failed to parse request body"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 413 {object} httperr.Response "Request body is too large"
+// @Failure 422 {object} httperr.Response "Invalid field values"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
 // @Router /actor [post]
-func AddActorHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AddActorHandler(w http.ResponseWriter, r *http.Request) {
 	var newActor models.Actor
-	err := json.NewDecoder(r.Body).Decode(&newActor)
+	err := decodeJSON(w, r, &newActor)
 	if err != nil {
-		http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	err = postgres.AddActor(newActor)
+	if err := validation.CreateActor(newActor); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	actor, err := h.storage.AddActor(r.Context(), newActor)
 	if err != nil {
-		http.Error(w, "Failed to add actor: "+err.Error(), http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	writeCreated(w, r, "/api/v1/actor/"+strconv.FormatInt(actor.ID, 10), actor)
 }
 
 // @Summary Получить список всех актеров
@@ -95,28 +99,50 @@ func AddActorHandler(w http.ResponseWriter, r *http.Request) {
 // @Tags actors
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Actor
-// @Failure 500 {string} string "Internal server error"
+// @Param limit query integer false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "Курсор из next_cursor или prev_cursor предыдущего ответа"
+// @Param include query string false "films - добавить к каждому актеру его фильмы" Enums(films)
+// @Success 200 {object} models.Page[models.Actor] "Страница актеров, с include=films - models.Page[models.ActorWithFilms]"
+// @Failure 400 {object} httperr.Response "Invalid limit, cursor or include"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
 // @Router /actors [get]
-func ActorsHandler(w http.ResponseWriter, r *http.Request) {
-	actors, err := postgres.GetAllActors()
-	if err != nil {
-		http.Error(w, "Failed to get actors", http.StatusInternalServerError)
+func (h *Handler) ActorsHandler(w http.ResponseWriter, r *http.Request) {
+	q, ok := pageQuery(r)
+	if !ok {
+		httperr.Write(w, r, httperr.BadRequest(invalidLimitMessage))
 		return
 	}
 
-	if actors == nil {
-		actors = []models.Actor{}
+	withFilms, err := includeFilms(r)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
 	}
 
-	actorsJSON, err := json.Marshal(actors)
+	actors, err := h.storage.GetAllActors(r.Context(), q)
 	if err != nil {
-		http.Error(w, "Failed to marshal actors", http.StatusInternalServerError)
+		httperr.Write(w, r, err)
+		return
+	}
+
+	if withFilms {
+		items, err := h.actorsWithFilms(r.Context(), actors.Items)
+		if err != nil {
+			httperr.Write(w, r, err)
+			return
+		}
+		writeJSON(w, r, models.Page[models.ActorWithFilms]{
+			Items:      items,
+			NextCursor: actors.NextCursor,
+			PrevCursor: actors.PrevCursor,
+			Total:      actors.Total,
+		})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(actorsJSON)
+	writeJSON(w, r, actors)
 }
 
 // @Summary Удалить актера по ID
@@ -125,108 +151,228 @@ func ActorsHandler(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path integer true "ID актера"
+// @Param cascade query boolean false "Убрать актера из состава фильмов вместо отказа в удалении" default(false)
 // @Success 200 {string} string "Actor deleted"
-// @Failure 400 {string} string "Invalid actor ID"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} httperr.Response "Invalid actor ID or cascade"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Actor not found"
+// @Failure 409 {object} httperr.Response "Actor is in the cast of some films, listed in details"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
 // @Router /actor/{id} [delete]
-func DeleteActor(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
-	id_str := parts[len(parts)-1]
+func (h *Handler) DeleteActor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "actor")
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	cascade := false
+	if raw := r.URL.Query().Get("cascade"); raw != "" {
+		var err error
+		cascade, err = strconv.ParseBool(raw)
+		if err != nil {
+			httperr.Write(w, r, httperr.BadRequest("Invalid cascade, expected true or false"))
+			return
+		}
+	}
 
-	if id_str == "" {
-		http.Error(w, "Missing actor ID", http.StatusBadRequest)
+	err = h.storage.DeleteActor(r.Context(), id, cascade)
+	if err != nil {
+		httperr.Write(w, r, err)
 		return
 	}
 
-	id, err := strconv.Atoi(id_str)
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Изменить актера
+// @Description Изменение отдельных полей актера по JSON Merge Patch (RFC 7396): отсутствующие поля не меняются, null очищает поле.
+// @Description Очистить можно sex и birthday, name - нет
+// @Tags actors
+// @Accept json
+// @Produce json
+// @Param id path integer true "ID актера"
+// @Param actor body models.ActorPatch true "Изменяемые поля"
+// @Success 200 {object} models.Actor "Актер после изменения"
+// @Failure 400 {object} httperr.Response "Invalid actor ID or failed to parse request body"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Actor not found"
+// @Failure 413 {object} httperr.Response "Request body is too large"
+// @Failure 422 {object} httperr.Response "Invalid field values"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /actor/{id} [patch]
+func (h *Handler) UpdateActor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "actor")
 	if err != nil {
-		http.Error(w, "Invalid actor ID", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	err = postgres.DeleteActor(id)
+	var patch models.ActorPatch
+	err = decodeJSON(w, r, &patch)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to delete actor: %v", err), http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	if err := validation.ActorPatch(patch); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	actor, err := h.storage.UpdateActor(r.Context(), id, patch)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, actor)
 }
 
-// @Summary Обновить информацию об актере
-// @Description Обновление информации об актере в базе данных по его идентификатору
+// @Summary Заменить актера
+// @Description Замена всех полей актера: отсутствующие в запросе поля очищаются.
 // @Tags actors
 // @Accept json
 // @Produce json
 // @Param id path integer true "ID актера"
-// @Param actor body models.Actor true "Информация об обновленном актере"
-// @Success 200 {string} string "Actor updated"
-// @Failure 400 {string} string "Invalid actor ID or failed to decode request body"
-// @Failure 500 {string} string "Internal server error"
-// @Router /actor/{id} [patch]
-func UpdateActor(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
-	id_str := parts[len(parts)-1]
-	id, err := strconv.Atoi(id_str)
+// @Param actor body models.Actor true "Новые данные, id в теле игнорируется"
+// @Success 200 {object} models.Actor "Актер после замены"
+// @Failure 400 {object} httperr.Response "Invalid actor ID or failed to parse request body"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Actor not found"
+// @Failure 413 {object} httperr.Response "Request body is too large"
+// @Failure 422 {object} httperr.Response "Invalid field values"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /actor/{id} [put]
+func (h *Handler) ReplaceActor(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "actor")
 	if err != nil {
-		http.Error(w, "Invalid actor ID", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	var updatedActor models.Actor
-	err = json.NewDecoder(r.Body).Decode(&updatedActor)
+	var replacement models.Actor
+	err = decodeJSON(w, r, &replacement)
 	if err != nil {
-		http.Error(w, "Failed to decode request body", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	err = postgres.UpdateActor(id, updatedActor)
+	if err := validation.CreateActor(replacement); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	actor, err := h.storage.UpdateActor(r.Context(), id, replacement.Patch())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update film: %v", err), http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	writeJSON(w, r, actor)
 }
 
-// @Summary Получить список всех фильмов, в которых участвовал актер
-// @Description Получение списка всех фильмов, в которых участвовал актер, по его идентификатору
+// @Summary Получить состав фильма
+// @Description Устаревший путь, используйте /film/{id}/actors
 // @Tags film_actors
 // @Accept json
 // @Produce json
-// @Param id path integer true "ID актера"
-// @Success 200 {array} models.Film
-// @Failure 400 {string} string "Invalid actor ID"
-// @Failure 500 {string} string "Internal server error"
+// @Param id path integer true "ID фильма"
+// @Param limit query integer false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "Курсор из next_cursor или prev_cursor предыдущего ответа"
+// @Success 200 {object} models.Page[models.Actor]
+// @Failure 400 {object} httperr.Response "Invalid film ID, limit or cursor"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 404 {object} httperr.Response "Film not found"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Deprecated
 // @Router /film_actors/{id} [get]
-func FindActorsFilm(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
-	id_str := parts[len(parts)-1]
+func (h *Handler) FindActorsFilm(w http.ResponseWriter, r *http.Request) {
+	h.FilmActors(w, r)
+}
 
-	if id_str == "" {
-		http.Error(w, "Missing film ID", http.StatusBadRequest)
+// @Summary Получить фильмы актера
+// @Description Получение фильмов, в которых снимался актер. По умолчанию фильмы отсортированы по дате выхода по возрастанию, фильмы без даты идут последними
+// @Tags actors
+// @Accept json
+// @Produce json
+// @Param id path integer true "ID актера"
+// @Param sort query string false "Поле сортировки" Enums(name, rating, release) default(release)
+// @Param order query string false "Направление сортировки, по умолчанию desc для rating и asc для остальных полей" Enums(asc, desc)
+// @Param limit query integer false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "Курсор из next_cursor или prev_cursor предыдущего ответа"
+// @Success 200 {object} models.Page[models.Film]
+// @Failure 400 {object} httperr.Response "Invalid actor ID, sort, order, limit or cursor"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 404 {object} httperr.Response "Actor not found"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /actor/{id}/films [get]
+func (h *Handler) ActorFilms(w http.ResponseWriter, r *http.Request) {
+	actorID, err := pathID(r, "id", "actor")
+	if err != nil {
+		httperr.Write(w, r, err)
 		return
 	}
 
-	id, err := strconv.Atoi(id_str)
+	q, err := filmQuery(r)
 	if err != nil {
-		http.Error(w, "Invalid film ID", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
-	actors, err := postgres.GetActorsByFilmID(id)
+	if q.SortBy == "" {
+		q.SortBy = storage.SortByRelease
+	}
+
+	films, err := h.storage.GetFilmsByActorID(r.Context(), actorID, q)
 	if err != nil {
-		http.Error(w, "Failed to get actors", http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	// Отправляем актеров в формате JSON
-	actorsJSON, err := json.Marshal(actors)
+	writeJSON(w, r, films)
+}
+
+// includeFilms разбирает параметр include: поддерживается только films.
+func includeFilms(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("include") {
+	case "":
+		return false, nil
+	case "films":
+		return true, nil
+	default:
+		return false, httperr.BadRequest("Invalid include, expected films")
+	}
+}
+
+// actorsWithFilms добавляет к актерам их фильмы, загружая фильмы всех
+// актеров одним запросом.
+func (h *Handler) actorsWithFilms(ctx context.Context, actors []models.Actor) ([]models.ActorWithFilms, error) {
+	ids := make([]int64, 0, len(actors))
+	for _, actor := range actors {
+		ids = append(ids, actor.ID)
+	}
+
+	films, err := h.storage.GetFilmsByActorIDs(ctx, ids)
 	if err != nil {
-		http.Error(w, "Failed to marshal actors", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(actorsJSON)
+	result := make([]models.ActorWithFilms, 0, len(actors))
+	for i, actor := range actors {
+		actorFilms := films[ids[i]]
+		if actorFilms == nil {
+			actorFilms = []models.Film{}
+		}
+		result = append(result, models.ActorWithFilms{Actor: actor, Films: actorFilms})
+	}
 
+	return result, nil
 }
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"vk/internal/models"
+	"vk/internal/server/httperr"
+	"vk/internal/validation"
+)
+
+// @Summary Получить состав фильма
+// @Description Получение актеров фильма по возрастанию их идентификаторов
+// @Tags films
+// @Accept json
+// @Produce json
+// @Param id path integer true "ID фильма"
+// @Param limit query integer false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "Курсор из next_cursor или prev_cursor предыдущего ответа"
+// @Success 200 {object} models.Page[models.Actor]
+// @Failure 400 {object} httperr.Response "Invalid film ID, limit or cursor"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 404 {object} httperr.Response "Film not found"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /film/{id}/actors [get]
+func (h *Handler) FilmActors(w http.ResponseWriter, r *http.Request) {
+	filmID, err := pathID(r, "id", "film")
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	q, ok := pageQuery(r)
+	if !ok {
+		httperr.Write(w, r, httperr.BadRequest(invalidLimitMessage))
+		return
+	}
+
+	actors, err := h.storage.GetActorsByFilmID(r.Context(), filmID, q)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, actors)
+}
+
+// @Summary Добавить актеров в состав фильма
+// @Description Добавление актеров в состав фильма по их идентификаторам. Актеры, уже входящие в состав, пропускаются
+// @Tags films
+// @Accept json
+// @Produce json
+// @Param id path integer true "ID фильма"
+// @Param cast body models.FilmCast true "ID добавляемых актеров"
+// @Success 200 {array} models.Actor "Состав фильма после изменения"
+// @Failure 400 {object} httperr.Response "Invalid film ID or failed to parse request body"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Film not found"
+// @Failure 413 {object} httperr.Response "Request body is too large"
+// @Failure 422 {object} httperr.Response "Invalid or unknown actor IDs"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /film/{id}/actors [post]
+func (h *Handler) AddFilmActors(w http.ResponseWriter, r *http.Request) {
+	filmID, err := pathID(r, "id", "film")
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	var cast models.FilmCast
+	err = decodeJSON(w, r, &cast)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	if err := validation.AddCast(cast); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	actors, err := h.storage.AddFilmActors(r.Context(), filmID, cast.ActorIDs)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, actors)
+}
+
+// @Summary Заменить состав фильма
+// @Description Замена всего состава фильма на актеров с указанными идентификаторами. Пустой список очищает состав
+// @Tags films
+// @Accept json
+// @Produce json
+// @Param id path integer true "ID фильма"
+// @Param cast body models.FilmCast true "ID актеров нового состава"
+// @Success 200 {array} models.Actor "Состав фильма после изменения"
+// @Failure 400 {object} httperr.Response "Invalid film ID or failed to parse request body"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Film not found"
+// @Failure 413 {object} httperr.Response "Request body is too large"
+// @Failure 422 {object} httperr.Response "Invalid or unknown actor IDs"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /film/{id}/actors [put]
+func (h *Handler) ReplaceFilmActors(w http.ResponseWriter, r *http.Request) {
+	filmID, err := pathID(r, "id", "film")
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	var cast models.FilmCast
+	err = decodeJSON(w, r, &cast)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	if err := validation.ReplaceCast(cast); err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	actors, err := h.storage.ReplaceFilmActors(r.Context(), filmID, cast.ActorIDs)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, actors)
+}
+
+// @Summary Убрать актера из состава фильма
+// @Description Удаление актера из состава фильма. Сам актер не удаляется
+// @Tags films
+// @Accept json
+// @Produce json
+// @Param id path integer true "ID фильма"
+// @Param actor_id path integer true "ID актера"
+// @Success 200 {array} models.Actor "Состав фильма после изменения"
+// @Failure 400 {object} httperr.Response "Invalid film ID or actor ID"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Film not found or actor is not in its cast"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /film/{id}/actors/{actor_id} [delete]
+func (h *Handler) RemoveFilmActor(w http.ResponseWriter, r *http.Request) {
+	filmID, err := pathID(r, "id", "film")
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	actorID, err := pathID(r, "actor_id", "actor")
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	actors, err := h.storage.RemoveFilmActor(r.Context(), filmID, actorID)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, actors)
+}
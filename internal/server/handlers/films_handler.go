@@ -1,81 +1,124 @@
 package handlers
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 	"vk/internal/models"
-	postgres "vk/internal/storage"
+	"vk/internal/server/httperr"
+	"vk/internal/validation"
 )
 
 // @Summary Получить список всех фильмов
-// @Description Получение списка всех фильмов
+// @Description Получение списка всех фильмов. По умолчанию фильмы отсортированы по рейтингу по убыванию
 // @Tags films
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Film
-// @Failure 500 {string} string "Internal server error"
+// @Param sort query string false "Поле сортировки" Enums(name, rating, release) default(rating)
+// @Param order query string false "Направление сортировки, по умолчанию desc для rating и asc для остальных полей" Enums(asc, desc)
+// @Param limit query integer false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "Курсор из next_cursor или prev_cursor предыдущего ответа"
+// @Success 200 {object} models.Page[models.Film]
+// @Failure 400 {object} httperr.Response "Invalid sort, order, limit or cursor"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
 // @Router /films [get]
-func FilmsHandler(w http.ResponseWriter, r *http.Request) {
-	films, err := postgres.GetAllFilms()
+func (h *Handler) FilmsHandler(w http.ResponseWriter, r *http.Request) {
+	q, err := filmQuery(r)
 	if err != nil {
-		http.Error(w, "Failed to get films", http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	if films == nil {
-		films = []models.Film{}
+	films, err := h.storage.GetAllFilms(r.Context(), q)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, films)
+}
+
+// @Summary Поиск фильмов
+// @Description Поиск фильмов по фрагменту названия или имени актера без учета регистра. Фильмы отсортированы по ID
+// @Tags films
+// @Accept json
+// @Produce json
+// @Param q query string true "Фрагмент названия фильма или имени актера, не короче 2 символов"
+// @Param limit query integer false "Размер страницы, от 1 до 100" default(20)
+// @Param cursor query string false "Курсор из next_cursor или prev_cursor предыдущего ответа"
+// @Success 200 {object} models.Page[models.Film]
+// @Failure 400 {object} httperr.Response "Missing or too short search query, invalid limit or cursor"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /films/search [get]
+func (h *Handler) SearchFilms(w http.ResponseWriter, r *http.Request) {
+	fragment := strings.TrimSpace(r.URL.Query().Get("q"))
+	if fragment == "" {
+		httperr.Write(w, r, httperr.BadRequest("Missing search query"))
+		return
+	}
+	if utf8.RuneCountInString(fragment) < minSearchLength {
+		httperr.Write(w, r, httperr.BadRequest(fmt.Sprintf("Search query must be at least %d characters long", minSearchLength)))
+		return
 	}
 
-	filmsJSON, err := json.Marshal(films)
+	q, ok := pageQuery(r)
+	if !ok {
+		httperr.Write(w, r, httperr.BadRequest(invalidLimitMessage))
+		return
+	}
+
+	films, err := h.storage.SearchFilms(r.Context(), fragment, q)
 	if err != nil {
-		http.Error(w, "Failed to marshal films", http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(filmsJSON)
+	writeJSON(w, r, films)
 }
 
 // @Summary Добавить новый фильм
-// @Description Добавление нового фильма
+// @Description Добавление нового фильма. Актеры задаются ID существующего актера (число) или объектом нового актера, который создается вместе с фильмом.
+// @Description Имя существующего актера (строка) принимается, только если это разрешено в конфиге, и должно быть однозначным
 // @Tags films
 // @Accept json
 // @Produce json
 // @Param film body models.CreateFilm true "Новый фильм"
-// @Success 201 {string} string "Film added successfully"
-// @Failure 400 {string} string "Failed to parse request body"
-// @Failure 500 {string} string "Failed to add film"
+// @Success 201 {object} models.FilmWithActors "Созданный фильм вместе с составом"
+// @Header 201 {string} Location "/api/v1/film/{id}"
+// @Failure 400 {object} httperr.Response "Failed to parse request body"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 413 {object} httperr.Response "Request body is too large"
+// @Failure 422 {object} httperr.Response "Invalid field values, unknown actors or ambiguous actor name"
+// @Failure 500 {object} httperr.Response "Failed to add film"
+// @Security BasicAuth
 // @Router /film [post]
-func AddFilmHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) AddFilmHandler(w http.ResponseWriter, r *http.Request) {
 	var newFilm models.CreateFilm
-	err := json.NewDecoder(r.Body).Decode(&newFilm)
+	err := decodeJSON(w, r, &newFilm)
 	if err != nil {
-		fmt.Println(err)
-		http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	err = postgres.AddFilm(newFilm)
-	if err != nil {
-		http.Error(w, "Failed to add film", http.StatusInternalServerError)
+	if err := validation.CreateFilm(newFilm, h.options.AllowActorNames); err != nil {
+		httperr.Write(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-}
-
-func FilmHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodDelete:
-		DeleteFilm(w, r)
-	case http.MethodGet:
-		FindFilm(w, r)
-	case http.MethodPatch:
-		UpdateFilm(w, r)
+	film, err := h.storage.AddFilm(r.Context(), newFilm)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
 	}
+
+	writeCreated(w, r, "/api/v1/film/"+strconv.FormatInt(film.ID, 10), film)
 }
 
 // @Summary Получить информацию о фильме по ID
@@ -85,107 +128,145 @@ func FilmHandler(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path integer true "ID фильма"
 // @Success 200 {object} models.Film
-// @Failure 400 {string} string "Missing film ID or invalid film ID"
-// @Failure 404 {string} string "Film not found"
-// @Failure 500 {string} string "Internal server error"
+// @Failure 400 {object} httperr.Response "Invalid film ID"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 404 {object} httperr.Response "Film not found"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
 // @Router /film/{id} [get]
-func FindFilm(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
-	id_str := parts[len(parts)-1]
-
-	if id_str == "" {
-		http.Error(w, "Missing film ID", http.StatusBadRequest)
+func (h *Handler) FindFilm(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "film")
+	if err != nil {
+		httperr.Write(w, r, err)
 		return
 	}
 
-	id, err := strconv.Atoi(id_str)
+	film, err := h.storage.FindFilm(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Invalid film ID", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	film, err := postgres.FindFilm(id)
+	writeJSON(w, r, film)
+}
+
+// @Summary Удалить фильм
+// @Description Удаление фильма по его идентификатору вместе со связями с актерами
+// @Tags films
+// @Accept json
+// @Produce json
+// @Param id path integer true "ID фильма"
+// @Success 200 {string} string "Film deleted successfully"
+// @Failure 400 {object} httperr.Response "Invalid film ID"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Film not found"
+// @Failure 500 {object} httperr.Response "Failed to delete film"
+// @Security BasicAuth
+// @Router /film/{id} [delete]
+func (h *Handler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "film")
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to find film: %v", err), http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	filmsJSON, err := json.Marshal(film)
+	err = h.storage.DeleteFilm(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Failed to marshal films", http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(filmsJSON)
+	w.WriteHeader(http.StatusOK)
 }
 
-// @Summary Удалить фильм
-// @Description Удаление фильма по его идентификатору
+// @Summary Изменить фильм
+// @Description Изменение отдельных полей фильма по JSON Merge Patch (RFC 7396): отсутствующие поля не меняются, null очищает поле.
+// @Description Очистить можно description и release, name и rating - нет
 // @Tags films
 // @Accept json
 // @Produce json
 // @Param id path integer true "ID фильма"
-// @Success 200 {string} string "Film deleted successfully"
-// @Failure 400 {string} string "Missing film ID or invalid film ID"
-// @Failure 500 {string} string "Failed to delete film"
-// @Router /film/{id} [delete]
-func DeleteFilm(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
-	id_str := parts[len(parts)-1]
-
-	if id_str == "" {
-		http.Error(w, "Missing film ID", http.StatusBadRequest)
+// @Param film body models.FilmPatch true "Изменяемые поля"
+// @Success 200 {object} models.Film "Фильм после изменения"
+// @Failure 400 {object} httperr.Response "Invalid film ID or failed to parse request body"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Film not found"
+// @Failure 413 {object} httperr.Response "Request body is too large"
+// @Failure 422 {object} httperr.Response "Invalid field values"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /film/{id} [patch]
+func (h *Handler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "film")
+	if err != nil {
+		httperr.Write(w, r, err)
 		return
 	}
 
-	id, err := strconv.Atoi(id_str)
+	var patch models.FilmPatch
+	err = decodeJSON(w, r, &patch)
 	if err != nil {
-		http.Error(w, "Invalid film ID", http.StatusBadRequest)
+		httperr.Write(w, r, err)
+		return
+	}
+
+	if err := validation.FilmPatch(patch); err != nil {
+		httperr.Write(w, r, err)
 		return
 	}
 
-	err = postgres.DeleteFilm(id)
+	film, err := h.storage.UpdateFilm(r.Context(), id, patch)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to delete film: %v", err), http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	writeJSON(w, r, film)
 }
 
-// @Summary Обновить информацию о фильме
-// @Description Обновление информации о фильме по его идентификатору
+// @Summary Заменить фильм
+// @Description Замена всех полей фильма: отсутствующие в запросе поля очищаются. Состав фильма не меняется
 // @Tags films
 // @Accept json
 // @Produce json
 // @Param id path integer true "ID фильма"
-// @Param film body models.Film true "Измененные данные фильма"
-// @Success 200 {string} string "Film updated successfully"
-// @Failure 400 {string} string "Invalid film ID or failed to decode request body"
-// @Failure 500 {string} string "Failed to update film"
-// @Router /film/{id} [patch]
-func UpdateFilm(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
-	id_str := parts[len(parts)-1]
-	id, err := strconv.Atoi(id_str)
+// @Param film body models.Film true "Новые данные, id в теле игнорируется"
+// @Success 200 {object} models.Film "Фильм после замены"
+// @Failure 400 {object} httperr.Response "Invalid film ID or failed to parse request body"
+// @Failure 401 {object} httperr.Response "Unauthorized"
+// @Failure 403 {object} httperr.Response "Forbidden"
+// @Failure 404 {object} httperr.Response "Film not found"
+// @Failure 413 {object} httperr.Response "Request body is too large"
+// @Failure 422 {object} httperr.Response "Invalid field values"
+// @Failure 500 {object} httperr.Response "Internal server error"
+// @Security BasicAuth
+// @Router /film/{id} [put]
+func (h *Handler) ReplaceFilm(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r, "id", "film")
 	if err != nil {
-		http.Error(w, "Invalid film ID", http.StatusBadRequest)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	var updatedFilm models.Film
-	err = json.NewDecoder(r.Body).Decode(&updatedFilm)
+	var replacement models.Film
+	err = decodeJSON(w, r, &replacement)
 	if err != nil {
-		http.Error(w, "Failed to decode request body", http.StatusBadRequest)
+		httperr.Write(w, r, err)
+		return
+	}
+
+	if err := validation.Film(replacement); err != nil {
+		httperr.Write(w, r, err)
 		return
 	}
 
-	err = postgres.UpdateFilm(id, updatedFilm)
+	film, err := h.storage.UpdateFilm(r.Context(), id, replacement.Patch())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update film: %v", err), http.StatusInternalServerError)
+		httperr.Write(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	writeJSON(w, r, film)
 }
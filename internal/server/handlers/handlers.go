@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"vk/internal/logger"
+	"vk/internal/server/httperr"
+	"vk/internal/storage"
+	"vk/internal/validation"
+)
+
+type Handler struct {
+	storage storage.Storage
+	options Options
+}
+
+// Options настраивает поведение обработчиков.
+type Options struct {
+	// AllowActorNames разрешает ссылаться на актеров по имени при создании фильма.
+	AllowActorNames bool
+}
+
+func New(storage storage.Storage, options Options) *Handler {
+	return &Handler{storage: storage, options: options}
+}
+
+// minSearchLength - минимальная длина фрагмента для поиска фильмов в символах.
+// Более короткий фрагмент совпадает почти со всеми фильмами.
+const minSearchLength = 2
+
+var invalidLimitMessage = fmt.Sprintf("Invalid limit, expected a number from 1 to %d", storage.MaxLimit)
+
+// pathID читает из пути запроса положительный ID, заданный в шаблоне
+// маршрута как {name}. what называет сущность в тексте ошибки 400.
+// ID принимается только в каноничной записи, без знака и ведущих нулей,
+// чтобы у каждой записи был один адрес.
+func pathID(r *http.Request, name, what string) (int64, error) {
+	raw := r.PathValue(name)
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 || raw != strconv.FormatInt(id, 10) {
+		return 0, httperr.BadRequest("Invalid " + what + " ID")
+	}
+
+	return id, nil
+}
+
+// pageQuery читает параметры limit и cursor списка,
+// false означает некорректный limit.
+func pageQuery(r *http.Request) (storage.PageQuery, bool) {
+	q := storage.PageQuery{Cursor: r.URL.Query().Get("cursor")}
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > storage.MaxLimit {
+			return storage.PageQuery{}, false
+		}
+		q.Limit = limit
+	}
+
+	return q, true
+}
+
+// filmQuery читает параметры sort, order, limit и cursor списка фильмов.
+func filmQuery(r *http.Request) (storage.FilmQuery, error) {
+	page, ok := pageQuery(r)
+	if !ok {
+		return storage.FilmQuery{}, httperr.BadRequest(invalidLimitMessage)
+	}
+
+	q := storage.FilmQuery{
+		SortBy:    r.URL.Query().Get("sort"),
+		Order:     r.URL.Query().Get("order"),
+		PageQuery: page,
+	}
+
+	switch q.SortBy {
+	case "", storage.SortByName, storage.SortByRating, storage.SortByRelease:
+	default:
+		return storage.FilmQuery{}, httperr.BadRequest("Invalid sort, expected name, rating or release")
+	}
+
+	switch q.Order {
+	case "", storage.OrderAsc, storage.OrderDesc:
+	default:
+		return storage.FilmQuery{}, httperr.BadRequest("Invalid order, expected asc or desc")
+	}
+
+	return q, nil
+}
+
+// maxBodySize ограничивает размер тела запроса.
+const maxBodySize = 1 << 20
+
+// decodeJSON читает тело запроса в v. Тело должно быть JSON-объектом:
+// null или массив вместо него дают 400, а не пустой патч или ошибку
+// валидации. Значение не того типа в известном поле дает ошибку валидации
+// этого поля, слишком большое тело - 413, остальные ошибки, в том числе
+// данные после JSON-значения, - 400.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return httperr.New(http.StatusRequestEntityTooLarge, httperr.CodePayloadTooLarge,
+			fmt.Sprintf("Request body is larger than %d bytes", maxBodySize))
+	}
+	if err == nil && !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		return httperr.BadRequest("Request body must be a JSON object")
+	}
+	if err == nil {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		err = dec.Decode(v)
+		if err == nil && dec.Decode(&json.RawMessage{}) != io.EOF {
+			err = errors.New("unexpected data after the JSON value")
+		}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			typeErr.Field = typeErrorField(body, v)
+		}
+		return validation.TypeError(typeErr)
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Debug("failed to parse request body", slog.String("error", err.Error()))
+		return httperr.BadRequest("Failed to parse request body")
+	}
+
+	return nil
+}
+
+// typeErrorField ищет ключ верхнего уровня, значение которого не подходит
+// для v по типу. encoding/json не указывает поле, если ошибку вернул
+// UnmarshalJSON самого поля, как у models.Field в патчах, поэтому ключи
+// проверяются по одному в порядке следования в теле.
+func typeErrorField(body []byte, v any) string {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return ""
+	}
+
+	target := reflect.TypeOf(v).Elem()
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		key, _ := tok.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return ""
+		}
+
+		single, err := json.Marshal(map[string]json.RawMessage{key: value})
+		if err != nil {
+			return ""
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(json.Unmarshal(single, reflect.New(target).Interface()), &typeErr) {
+			return key
+		}
+	}
+
+	return ""
+}
+
+// writeJSON отвечает 200 с v в теле.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// writeCreated отвечает 201 с созданной записью и ссылкой на нее в Location.
+func writeCreated(w http.ResponseWriter, r *http.Request, location string, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		httperr.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", location)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(body)
+}
@@ -0,0 +1,127 @@
+// Package httperr превращает ошибки в JSON-ответы API с постоянными кодами.
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"vk/internal/logger"
+	"vk/internal/requestid"
+	"vk/internal/storage"
+	"vk/internal/validation"
+)
+
+// Коды ошибок в поле code ответа. Клиенты могут на них полагаться,
+// в отличие от текста в поле message.
+const (
+	CodeBadRequest       = "bad_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeValidationFailed = "validation_failed"
+	CodeInternal         = "internal_error"
+)
+
+// Response - тело ответа с ошибкой.
+type Response struct {
+	Code      string `json:"code" example:"not_found"`
+	Message   string `json:"message" example:"film 1 not found"`
+	Details   any    `json:"details,omitempty" swaggertype:"object"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Error - ошибка, которую обработчик отдает клиенту как есть.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details any
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message)
+}
+
+// MethodNotAllowed отвечает 405 и перечисляет допустимые методы в заголовке Allow.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	Write(w, r, New(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method not allowed"))
+}
+
+// Write отвечает на запрос ошибкой err. Ошибки хранилища и валидации,
+// а также неверный курсор списка получают свой статус, остальные
+// считаются внутренними: клиент видит только общий текст, а подробности
+// попадают в лог запроса.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	e := classify(err)
+	if e.Status == http.StatusInternalServerError {
+		logger.FromContext(r.Context()).Error("request failed",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(Response{
+		Code:      e.Code,
+		Message:   e.Message,
+		Details:   e.Details,
+		RequestID: requestid.FromContext(r.Context()),
+	})
+}
+
+func classify(err error) *Error {
+	var httpErr *Error
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	var violations validation.Errors
+	if errors.As(err, &violations) {
+		return &Error{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    CodeValidationFailed,
+			Message: "Request validation failed",
+			Details: violations,
+		}
+	}
+
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		return BadRequest("Invalid cursor")
+	}
+
+	var storageErr *storage.Error
+	if errors.As(err, &storageErr) {
+		e := &Error{Message: storageErr.Message, Details: storageErr.Details}
+		switch {
+		case errors.Is(storageErr.Kind, storage.ErrNotFound):
+			e.Status, e.Code = http.StatusNotFound, CodeNotFound
+		case errors.Is(storageErr.Kind, storage.ErrConflict):
+			e.Status, e.Code = http.StatusConflict, CodeConflict
+		case errors.Is(storageErr.Kind, storage.ErrValidation):
+			e.Status, e.Code = http.StatusUnprocessableEntity, CodeValidationFailed
+		default:
+			return New(http.StatusInternalServerError, CodeInternal, "Internal server error")
+		}
+		return e
+	}
+
+	return New(http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"vk/internal/config"
+	"vk/internal/server/httperr"
+)
+
+type userKey struct{}
+
+// Auth проверяет Basic-аутентификацию и права роли на метод запроса:
+// роль user может только читать (GET, HEAD, OPTIONS), admin - все.
+// Без учетных данных или с неверными отвечает 401, без прав - 403.
+func Auth(users []config.User) func(http.Handler) http.Handler {
+	byName := make(map[string]config.User, len(users))
+	for _, user := range users {
+		byName[user.Name] = user
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name, password, ok := r.BasicAuth()
+			user, found := byName[name]
+			if !ok || !found || !passwordsEqual(user.Password, password) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="api", charset="UTF-8"`)
+				httperr.Write(w, r, httperr.New(http.StatusUnauthorized, httperr.CodeUnauthorized, "Unauthorized"))
+				return
+			}
+
+			if !allowed(user.Role, r.Method) {
+				httperr.Write(w, r, httperr.New(http.StatusForbidden, httperr.CodeForbidden, "Forbidden"))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userKey{}, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// UserFromContext возвращает пользователя, прошедшего аутентификацию.
+func UserFromContext(ctx context.Context) (config.User, bool) {
+	user, ok := ctx.Value(userKey{}).(config.User)
+	return user, ok
+}
+
+func allowed(role, method string) bool {
+	if role == config.RoleAdmin {
+		return true
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// passwordsEqual сравнивает хеши, чтобы время сравнения не зависело
+// ни от совпадающего префикса, ни от длины пароля.
+func passwordsEqual(expected, actual string) bool {
+	e := sha256.Sum256([]byte(expected))
+	a := sha256.Sum256([]byte(actual))
+
+	return subtle.ConstantTimeCompare(e[:], a[:]) == 1
+}
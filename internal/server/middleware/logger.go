@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"vk/internal/logger"
+	"vk/internal/requestid"
+)
+
+// Logger кладет в контекст логгер с идентификатором запроса и после ответа
+// пишет в лог метод, путь, статус, время обработки и размер тела ответа.
+// Должен стоять после RequestID.
+func Logger(log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqLog := log.With(slog.String("request_id", requestid.FromContext(r.Context())))
+			ctx := logger.WithContext(r.Context(), reqLog)
+
+			sw := &statusWriter{ResponseWriter: w}
+			start := time.Now()
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			reqLog.Info("request completed",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", sw.Status()),
+				slog.Duration("duration", time.Since(start)),
+				slog.Int("bytes", sw.bytes),
+			)
+		})
+	}
+}
+
+// statusWriter запоминает статус и число байт тела ответа.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+
+	return n, err
+}
+
+// Status возвращает отправленный статус, 200 если обработчик ничего не записал.
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+
+	return w.status
+}
+
+// Unwrap дает http.ResponseController доступ к исходному ResponseWriter.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"vk/internal/server/httperr"
+)
+
+// chain собирает middleware в том же порядке, что и server.SetupRouter.
+func chain(log *slog.Logger, h http.Handler) http.Handler {
+	return RequestID(Logger(log)(Recover(h)))
+}
+
+// captureLog возвращает логгер, который пишет в память, и функцию,
+// разбирающую записанные записи.
+func captureLog(t *testing.T) (*slog.Logger, func() []map[string]any) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	return log, func() []map[string]any {
+		var records []map[string]any
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var record map[string]any
+			if err := dec.Decode(&record); err != nil {
+				t.Fatalf("decode log record: %v", err)
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+// findRecord возвращает первую запись лога с сообщением msg.
+func findRecord(t *testing.T, records []map[string]any, msg string) map[string]any {
+	t.Helper()
+
+	for _, record := range records {
+		if record[slog.MessageKey] == msg {
+			return record
+		}
+	}
+	t.Fatalf("no %q record in %v", msg, records)
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	log, records := captureLog(t)
+	h := chain(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/films", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var got httperr.Response
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	id := rec.Header().Get(RequestIDHeader)
+	if got.Code != httperr.CodeInternal || got.RequestID == "" || got.RequestID != id {
+		t.Errorf("response = %+v, want %s with request_id %q", got, httperr.CodeInternal, id)
+	}
+
+	all := records()
+	panicked := findRecord(t, all, "panic recovered")
+	if panicked["panic"] != "boom" || panicked["request_id"] != id || panicked["stack"] == "" {
+		t.Errorf("panic record = %v", panicked)
+	}
+	if completed := findRecord(t, all, "request completed"); completed["status"] != float64(http.StatusInternalServerError) {
+		t.Errorf("access log status = %v, want 500", completed["status"])
+	}
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	log, _ := captureLog(t)
+	h := chain(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// статус уже отправлен, и ошибку в тело дописывать нельзя
+	if rec.Code != http.StatusAccepted || rec.Body.Len() != 0 {
+		t.Errorf("response = %d %q, want 202 without body", rec.Code, rec.Body)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "echoed", incoming: "req-42", keep: true},
+		{name: "missing", incoming: ""},
+		{name: "control characters", incoming: "req\n42"},
+		{name: "space", incoming: "req 42"},
+		{name: "non-ASCII", incoming: "запрос"},
+		{name: "too long", incoming: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := captureLog(t)
+			h := chain(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				httperr.Write(w, r, httperr.BadRequest("bad"))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.keep && id != tt.incoming {
+				t.Errorf("%s = %q, want %q", RequestIDHeader, id, tt.incoming)
+			}
+			if !tt.keep && (id == tt.incoming || !validRequestID(id)) {
+				t.Errorf("%s = %q, want a new ID", RequestIDHeader, id)
+			}
+
+			var got httperr.Response
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if got.RequestID != id {
+				t.Errorf("request_id = %q, want %q", got.RequestID, id)
+			}
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	log, records := captureLog(t)
+	h := chain(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/film?x=1", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	got := findRecord(t, records(), "request completed")
+	want := map[string]any{
+		"level":      "INFO",
+		"request_id": "req-42",
+		"method":     http.MethodPost,
+		"path":       "/api/v1/film",
+		"status":     float64(http.StatusCreated),
+		"bytes":      float64(len("hello")),
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+	if duration, ok := got["duration"].(float64); !ok || duration < 0 {
+		t.Errorf("duration = %v, want a non-negative number", got["duration"])
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"vk/internal/logger"
+	"vk/internal/server/httperr"
+)
+
+// Recover превращает панику в обработчике в ответ 500 с JSON-ошибкой
+// и пишет панику со стеком в лог запроса. Если ответ уже начат,
+// статус поменять нельзя, и паника только попадает в лог.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			logger.FromContext(r.Context()).Error("panic recovered",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("panic", fmt.Sprint(p)),
+				slog.String("stack", string(debug.Stack())),
+			)
+			if sw.status == 0 {
+				httperr.Write(w, r, httperr.New(http.StatusInternalServerError, httperr.CodeInternal, "Internal server error"))
+			}
+		}()
+
+		next.ServeHTTP(sw, r)
+	})
+}
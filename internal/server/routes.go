@@ -1,21 +1,108 @@
 package server
 
 import (
+	"log/slog"
 	"net/http"
+	"strings"
+
+	"vk/internal/config"
 	"vk/internal/server/handlers"
+	"vk/internal/server/httperr"
+	"vk/internal/server/middleware"
+	"vk/internal/storage"
 )
 
-func SetupRouter() *http.ServeMux {
+// SetupRouter собирает маршруты API и цепочку middleware вокруг них:
+// идентификатор запроса, лог запросов, восстановление после паники
+// и аутентификация.
+func SetupRouter(storage storage.Storage, cfg *config.Config, log *slog.Logger) http.Handler {
 	router := http.NewServeMux()
+	h := handlers.New(storage, handlers.Options{AllowActorNames: cfg.AllowActorNames})
+
+	router.HandleFunc("GET /api/v1/films", h.FilmsHandler)
+	router.HandleFunc("GET /api/v1/films/search", h.SearchFilms)
+	router.HandleFunc("POST /api/v1/film", h.AddFilmHandler)
+	router.HandleFunc("GET /api/v1/film/{id}", h.FindFilm)
+	router.HandleFunc("PATCH /api/v1/film/{id}", h.UpdateFilm)
+	router.HandleFunc("PUT /api/v1/film/{id}", h.ReplaceFilm)
+	router.HandleFunc("DELETE /api/v1/film/{id}", h.DeleteFilm)
+	router.HandleFunc("GET /api/v1/film/{id}/actors", h.FilmActors)
+	router.HandleFunc("POST /api/v1/film/{id}/actors", h.AddFilmActors)
+	router.HandleFunc("PUT /api/v1/film/{id}/actors", h.ReplaceFilmActors)
+	router.HandleFunc("DELETE /api/v1/film/{id}/actors/{actor_id}", h.RemoveFilmActor)
+	router.HandleFunc("GET /api/v1/film_actors/{id}", h.FindActorsFilm)
+
+	router.HandleFunc("GET /api/v1/actors", h.ActorsHandler)
+	router.HandleFunc("POST /api/v1/actor", h.AddActorHandler)
+	router.HandleFunc("GET /api/v1/actor/{id}", h.FindActor)
+	router.HandleFunc("PATCH /api/v1/actor/{id}", h.UpdateActor)
+	router.HandleFunc("PUT /api/v1/actor/{id}", h.ReplaceActor)
+	router.HandleFunc("DELETE /api/v1/actor/{id}", h.DeleteActor)
+	router.HandleFunc("GET /api/v1/actor/{id}/films", h.ActorFilms)
+
+	handler := middleware.Auth(cfg.Users)(withRouteErrors(router))
+	handler = middleware.Recover(handler)
+	handler = middleware.Logger(log)(handler)
+
+	return middleware.RequestID(handler)
+}
+
+// withRouteErrors заменяет текстовые ответы 404 и 405, которые ServeMux
+// отдает сам, на JSON-ошибки API и отвечает на OPTIONS списком методов
+// пути. Заголовок Allow для 405 по-прежнему вычисляет ServeMux.
+// OPTIONS, как и остальные методы, требует учетных данных, поэтому
+// CORS preflight браузера, который их не передает, получит 401.
+func withRouteErrors(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, pattern := mux.Handler(r)
+		if pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
 
-	router.HandleFunc("/api/v1/films", handlers.FilmsHandler)
-	router.HandleFunc("/api/v1/film/", handlers.FilmHandler)
-	router.HandleFunc("/api/v1/film", handlers.AddFilmHandler)
-	router.HandleFunc("/api/v1/film_actors/", handlers.FindActorsFilm)
+		rec := &routeRecorder{header: make(http.Header)}
+		handler.ServeHTTP(rec, r)
 
-	router.HandleFunc("/api/v1/actor/", handlers.ActorHandler)
-	router.HandleFunc("/api/v1/actor", handlers.AddActorHandler)
-	router.HandleFunc("/api/v1/actors", handlers.ActorsHandler)
+		switch rec.status {
+		case http.StatusNotFound:
+			httperr.Write(w, r, httperr.New(http.StatusNotFound, httperr.CodeNotFound, "Not found"))
+		case http.StatusMethodNotAllowed:
+			// OPTIONS обрабатывается здесь для любого пути, ServeMux о нем не знает
+			allowed := append(strings.Split(rec.header.Get("Allow"), ", "), http.MethodOptions)
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Allow", strings.Join(allowed, ", "))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			httperr.MethodNotAllowed(w, r, allowed...)
+		default:
+			// например, редирект на канонический путь
+			for key, values := range rec.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(rec.status)
+		}
+	})
+}
+
+// routeRecorder запоминает статус и заголовки ответа, который ServeMux
+// формирует сам, не отправляя тело клиенту.
+type routeRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *routeRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *routeRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
 
-	return router
+func (r *routeRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return len(b), nil
 }
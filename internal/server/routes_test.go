@@ -0,0 +1,411 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"vk/internal/config"
+	"vk/internal/models"
+	"vk/internal/server/httperr"
+	"vk/internal/storage/memory"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cfg := &config.Config{
+		Users: config.Users{
+			{Name: "admin", Password: "admin", Role: config.RoleAdmin},
+			{Name: "user", Password: "user", Role: config.RoleUser},
+		},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	srv := httptest.NewServer(SetupRouter(memory.New(), cfg, log))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// do выполняет запрос от имени пользователя user с паролем, равным имени,
+// и разбирает JSON-ответ в out, если он задан.
+func do(t *testing.T, srv *httptest.Server, user, method, path, body string, out any) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != "" {
+		req.SetBasicAuth(user, user)
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode response: %v", method, path, err)
+		}
+	}
+
+	return resp
+}
+
+func TestErrors(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		name       string
+		user       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "no credentials", method: http.MethodGet, path: "/api/v1/films", wantStatus: http.StatusUnauthorized, wantCode: httperr.CodeUnauthorized},
+		{name: "read only user", user: "user", method: http.MethodPost, path: "/api/v1/actor", body: `{"name":"Keanu"}`, wantStatus: http.StatusForbidden, wantCode: httperr.CodeForbidden},
+		{name: "unknown path", user: "admin", method: http.MethodGet, path: "/api/v1/nothing", wantStatus: http.StatusNotFound, wantCode: httperr.CodeNotFound},
+		{name: "nested path", user: "admin", method: http.MethodGet, path: "/api/v1/film/1/extra", wantStatus: http.StatusNotFound, wantCode: httperr.CodeNotFound},
+		{name: "unknown film", user: "admin", method: http.MethodGet, path: "/api/v1/film/42", wantStatus: http.StatusNotFound, wantCode: httperr.CodeNotFound},
+		{name: "invalid id", user: "admin", method: http.MethodGet, path: "/api/v1/film/abc", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "leading zero id", user: "admin", method: http.MethodGet, path: "/api/v1/film/01", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "signed id", user: "admin", method: http.MethodGet, path: "/api/v1/film/%2B1", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "invalid sort", user: "admin", method: http.MethodGet, path: "/api/v1/films?sort=id", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "invalid limit", user: "admin", method: http.MethodGet, path: "/api/v1/actors?limit=101", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "invalid cursor", user: "admin", method: http.MethodGet, path: "/api/v1/actors?cursor=abc", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "missing search query", user: "admin", method: http.MethodGet, path: "/api/v1/films/search?q=+", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "short search query", user: "admin", method: http.MethodGet, path: "/api/v1/films/search?q=%D1%8F", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "malformed body", user: "admin", method: http.MethodPost, path: "/api/v1/actor", body: `{"name":`, wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "null patch", user: "admin", method: http.MethodPatch, path: "/api/v1/film/1", body: `null`, wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "array patch", user: "admin", method: http.MethodPatch, path: "/api/v1/actor/1", body: ` []`, wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "null replacement", user: "admin", method: http.MethodPut, path: "/api/v1/actor/1", body: `null`, wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "array replacement", user: "admin", method: http.MethodPut, path: "/api/v1/film/1", body: `[]`, wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "invalid include", user: "admin", method: http.MethodGet, path: "/api/v1/actors?include=bogus", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "invalid actor include", user: "admin", method: http.MethodGet, path: "/api/v1/actor/1?include=bogus", wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "trailing data", user: "admin", method: http.MethodPost, path: "/api/v1/actor", body: `{"name":"Keanu"} {}`, wantStatus: http.StatusBadRequest, wantCode: httperr.CodeBadRequest},
+		{name: "body too large", user: "admin", method: http.MethodPost, path: "/api/v1/actor", body: `{"name":"` + strings.Repeat("a", 2<<20) + `"}`, wantStatus: http.StatusRequestEntityTooLarge, wantCode: httperr.CodePayloadTooLarge},
+		{name: "invalid fields", user: "admin", method: http.MethodPost, path: "/api/v1/film", body: `{"name":"","rating":11}`, wantStatus: http.StatusUnprocessableEntity, wantCode: httperr.CodeValidationFailed},
+		{name: "unknown actor", user: "admin", method: http.MethodPost, path: "/api/v1/film", body: `{"name":"Matrix","actors":[42]}`, wantStatus: http.StatusUnprocessableEntity, wantCode: httperr.CodeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got httperr.Response
+			resp := do(t, srv, tt.user, tt.method, tt.path, tt.body, &got)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", got.Code, tt.wantCode)
+			}
+			if got.RequestID == "" {
+				t.Error("request_id is empty")
+			}
+		})
+	}
+}
+
+func TestMethods(t *testing.T) {
+	srv := newTestServer(t)
+
+	var got httperr.Response
+	resp := do(t, srv, "admin", http.MethodPost, "/api/v1/films", "", &got)
+	if resp.StatusCode != http.StatusMethodNotAllowed || got.Code != httperr.CodeMethodNotAllowed {
+		t.Errorf("POST /films = %d %q, want 405", resp.StatusCode, got.Code)
+	}
+	if allow := resp.Header.Get("Allow"); !strings.Contains(allow, http.MethodGet) || !strings.Contains(allow, http.MethodOptions) {
+		t.Errorf("Allow = %q, want GET and OPTIONS", allow)
+	}
+
+	resp = do(t, srv, "user", http.MethodOptions, "/api/v1/film/1", "", nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("OPTIONS status = %d, want 204", resp.StatusCode)
+	}
+	if allow := resp.Header.Get("Allow"); !strings.Contains(allow, http.MethodPatch) {
+		t.Errorf("OPTIONS Allow = %q, want PATCH", allow)
+	}
+
+	resp = do(t, srv, "", http.MethodOptions, "/api/v1/film/1", "", nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("OPTIONS without credentials status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestFilmLifecycle(t *testing.T) {
+	srv := newTestServer(t)
+
+	var actor models.Actor
+	resp := do(t, srv, "admin", http.MethodPost, "/api/v1/actor", `{"name":"Keanu Reeves","sex":"male","birthday":"1964-09-02"}`, &actor)
+	if resp.StatusCode != http.StatusCreated || actor.ID == 0 {
+		t.Fatalf("POST /actor = %d %+v", resp.StatusCode, actor)
+	}
+
+	body := `{"name":"Matrix","description":"Red pill","rating":9,"release":"1999-03-31","actors":[` +
+		strconv.FormatInt(actor.ID, 10) + `,{"name":"Carrie-Anne Moss","sex":"female"}]}`
+	var film models.FilmWithActors
+	resp = do(t, srv, "admin", http.MethodPost, "/api/v1/film", body, &film)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /film status = %d", resp.StatusCode)
+	}
+	filmPath := "/api/v1/film/" + strconv.FormatInt(film.ID, 10)
+	if location := resp.Header.Get("Location"); location != filmPath {
+		t.Errorf("Location = %q, want %q", location, filmPath)
+	}
+	if len(film.Actors) != 2 {
+		t.Errorf("created film actors = %+v, want 2", film.Actors)
+	}
+
+	var patched models.Film
+	resp = do(t, srv, "admin", http.MethodPatch, filmPath, `{"description":null,"rating":8}`, &patched)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PATCH status = %d", resp.StatusCode)
+	}
+	want := models.Film{ID: film.ID, Name: "Matrix", Rating: 8, Release: models.NewDate(1999, 3, 31)}
+	if patched != want {
+		t.Errorf("PATCH = %+v, want %+v", patched, want)
+	}
+
+	var got models.Film
+	resp = do(t, srv, "user", http.MethodGet, filmPath, "", &got)
+	if resp.StatusCode != http.StatusOK || got != want {
+		t.Errorf("GET = %d %+v, want %+v", resp.StatusCode, got, want)
+	}
+
+	var actorFilms models.Page[models.Film]
+	resp = do(t, srv, "user", http.MethodGet, "/api/v1/actor/"+strconv.FormatInt(actor.ID, 10)+"/films", "", &actorFilms)
+	if resp.StatusCode != http.StatusOK || len(actorFilms.Items) != 1 || actorFilms.Items[0].ID != film.ID {
+		t.Errorf("GET actor films = %d %+v", resp.StatusCode, actorFilms)
+	}
+
+	resp = do(t, srv, "admin", http.MethodDelete, filmPath, "", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DELETE status = %d, want 200", resp.StatusCode)
+	}
+	resp = do(t, srv, "admin", http.MethodGet, filmPath, "", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET deleted film status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestFilmCast(t *testing.T) {
+	srv := newTestServer(t)
+
+	var ids []string
+	for _, name := range []string{"Keanu Reeves", "Carrie-Anne Moss", "Laurence Fishburne"} {
+		var actor models.Actor
+		if resp := do(t, srv, "admin", http.MethodPost, "/api/v1/actor", `{"name":"`+name+`"}`, &actor); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("POST /actor status = %d", resp.StatusCode)
+		}
+		ids = append(ids, strconv.FormatInt(actor.ID, 10))
+	}
+	keanu, carrie, laurence := ids[0], ids[1], ids[2]
+
+	var film models.FilmWithActors
+	if resp := do(t, srv, "admin", http.MethodPost, "/api/v1/film", `{"name":"Matrix","actors":[`+keanu+`]}`, &film); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /film status = %d", resp.StatusCode)
+	}
+	castPath := "/api/v1/film/" + strconv.FormatInt(film.ID, 10) + "/actors"
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCast   string
+	}{
+		// уже входящий в состав актер пропускается
+		{name: "add", method: http.MethodPost, path: castPath, body: `{"actor_ids":[` + laurence + `,` + keanu + `]}`, wantStatus: http.StatusOK, wantCast: "Keanu Reeves,Laurence Fishburne"},
+		{name: "add duplicate ids", method: http.MethodPost, path: castPath, body: `{"actor_ids":[` + carrie + `,` + carrie + `]}`, wantStatus: http.StatusUnprocessableEntity, wantCast: "Keanu Reeves,Laurence Fishburne"},
+		{name: "remove non-member", method: http.MethodDelete, path: castPath + "/" + carrie, wantStatus: http.StatusNotFound, wantCast: "Keanu Reeves,Laurence Fishburne"},
+		{name: "remove", method: http.MethodDelete, path: castPath + "/" + keanu, wantStatus: http.StatusOK, wantCast: "Laurence Fishburne"},
+		{name: "replace", method: http.MethodPut, path: castPath, body: `{"actor_ids":[` + carrie + `,` + keanu + `]}`, wantStatus: http.StatusOK, wantCast: "Keanu Reeves,Carrie-Anne Moss"},
+		{name: "replace with empty list", method: http.MethodPut, path: castPath, body: `{"actor_ids":[]}`, wantStatus: http.StatusOK, wantCast: ""},
+	}
+
+	for _, tt := range tests {
+		resp := do(t, srv, "admin", tt.method, tt.path, tt.body, nil)
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.wantStatus)
+		}
+
+		var cast models.Page[models.Actor]
+		do(t, srv, "user", http.MethodGet, castPath, "", &cast)
+		if got := actorNames(cast.Items); got != tt.wantCast {
+			t.Errorf("%s: cast = %q, want %q", tt.name, got, tt.wantCast)
+		}
+	}
+
+	// Неизвестные актеры перечисляются в details, состав не меняется.
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		var got httperr.Response
+		resp := do(t, srv, "admin", method, castPath, `{"actor_ids":[`+keanu+`,42,43]}`, &got)
+		if resp.StatusCode != http.StatusUnprocessableEntity || got.Code != httperr.CodeValidationFailed {
+			t.Errorf("%s unknown actors = %d %q, want 422", method, resp.StatusCode, got.Code)
+		}
+		if details, _ := json.Marshal(got.Details); string(details) != "[42,43]" {
+			t.Errorf("%s unknown actors details = %s, want [42,43]", method, details)
+		}
+	}
+	var cast models.Page[models.Actor]
+	do(t, srv, "user", http.MethodGet, castPath, "", &cast)
+	if len(cast.Items) != 0 {
+		t.Errorf("cast after unknown actors = %+v, want empty", cast.Items)
+	}
+}
+
+// actorNames перечисляет имена актеров через запятую.
+func actorNames(actors []models.Actor) string {
+	names := make([]string, 0, len(actors))
+	for _, actor := range actors {
+		names = append(names, actor.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestIncludeFilms(t *testing.T) {
+	srv := newTestServer(t)
+
+	var ids []string
+	for _, name := range []string{"Keanu Reeves", "Carrie-Anne Moss"} {
+		var actor models.Actor
+		if resp := do(t, srv, "admin", http.MethodPost, "/api/v1/actor", `{"name":"`+name+`"}`, &actor); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("POST /actor status = %d", resp.StatusCode)
+		}
+		ids = append(ids, strconv.FormatInt(actor.ID, 10))
+	}
+	keanu, carrie := ids[0], ids[1]
+	for _, body := range []string{
+		`{"name":"John Wick","release":"2014-10-24","actors":[` + keanu + `]}`,
+		`{"name":"Matrix","release":"1999-03-31","actors":[` + keanu + `]}`,
+	} {
+		if resp := do(t, srv, "admin", http.MethodPost, "/api/v1/film", body, nil); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("POST /film status = %d", resp.StatusCode)
+		}
+	}
+
+	// films читается как есть, чтобы отличить [] от отсутствующего поля.
+	type actorFilms struct {
+		Name  string          `json:"name"`
+		Films json.RawMessage `json:"films"`
+	}
+	filmNames := func(raw json.RawMessage) string {
+		if raw == nil {
+			return "<none>"
+		}
+		var films []models.Film
+		if err := json.Unmarshal(raw, &films); err != nil || films == nil {
+			return string(raw)
+		}
+		names := make([]string, 0, len(films))
+		for _, film := range films {
+			names = append(names, film.Name)
+		}
+		return "[" + strings.Join(names, ",") + "]"
+	}
+
+	var page models.Page[actorFilms]
+	do(t, srv, "user", http.MethodGet, "/api/v1/actors?include=films", "", &page)
+	var got []string
+	for _, actor := range page.Items {
+		got = append(got, actor.Name+" "+filmNames(actor.Films))
+	}
+	// фильмы актера идут по дате выхода, у актера без фильмов - пустой список
+	want := []string{"Keanu Reeves [Matrix,John Wick]", "Carrie-Anne Moss []"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("GET /actors?include=films = %q, want %q", got, want)
+	}
+
+	page = models.Page[actorFilms]{}
+	do(t, srv, "user", http.MethodGet, "/api/v1/actors", "", &page)
+	if len(page.Items) == 0 || page.Items[0].Films != nil {
+		t.Errorf("GET /actors = %+v, want actors without films", page.Items)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/v1/actor/" + keanu + "?include=films", want: "[Matrix,John Wick]"},
+		{path: "/api/v1/actor/" + carrie + "?include=films", want: "[]"},
+		{path: "/api/v1/actor/" + keanu, want: "<none>"},
+	}
+
+	for _, tt := range tests {
+		var actor actorFilms
+		do(t, srv, "user", http.MethodGet, tt.path, "", &actor)
+		if got := filmNames(actor.Films); got != tt.want {
+			t.Errorf("GET %s films = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPagination(t *testing.T) {
+	srv := newTestServer(t)
+
+	for i := 1; i <= 5; i++ {
+		body := `{"name":"Matrix ` + strconv.Itoa(i) + `","rating":` + strconv.Itoa(i) + `}`
+		if resp := do(t, srv, "admin", http.MethodPost, "/api/v1/film", body, nil); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("POST /film status = %d", resp.StatusCode)
+		}
+	}
+	do(t, srv, "admin", http.MethodPost, "/api/v1/film", `{"name":"Other","rating":10}`, nil)
+
+	tests := []struct {
+		name  string
+		path  string
+		want  []string
+		total int
+	}{
+		{name: "films by rating", path: "/api/v1/films?limit=2", want: []string{"Other", "Matrix 5", "Matrix 4", "Matrix 3", "Matrix 2", "Matrix 1"}, total: 6},
+		{name: "films by name", path: "/api/v1/films?sort=name&limit=4", want: []string{"Matrix 1", "Matrix 2", "Matrix 3", "Matrix 4", "Matrix 5", "Other"}, total: 6},
+		{name: "search", path: "/api/v1/films/search?q=MATRIX&limit=2", want: []string{"Matrix 1", "Matrix 2", "Matrix 3", "Matrix 4", "Matrix 5"}, total: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			var last models.Page[models.Film]
+			next := tt.path
+			for next != "" {
+				var page models.Page[models.Film]
+				if resp := do(t, srv, "user", http.MethodGet, next, "", &page); resp.StatusCode != http.StatusOK {
+					t.Fatalf("GET %s status = %d", next, resp.StatusCode)
+				}
+				if page.Total != tt.total {
+					t.Errorf("total = %d, want %d", page.Total, tt.total)
+				}
+				for _, film := range page.Items {
+					names = append(names, film.Name)
+				}
+
+				last, next = page, ""
+				if page.NextCursor != "" {
+					next = tt.path + "&cursor=" + page.NextCursor
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("names = %v, want %v", names, tt.want)
+			}
+
+			// Шаг назад с последней страницы возвращает предыдущую.
+			var prev models.Page[models.Film]
+			do(t, srv, "user", http.MethodGet, tt.path+"&cursor="+last.PrevCursor, "", &prev)
+			n := len(tt.want) - len(last.Items)
+			if len(prev.Items) == 0 || prev.Items[len(prev.Items)-1].Name != tt.want[n-1] {
+				t.Errorf("previous page = %+v, want it to end with %s", prev.Items, tt.want[n-1])
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Виды ошибок хранилища. Проверяются через errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+)
+
+// Error - ошибка хранилища, которую можно показать клиенту. Kind - один
+// из ErrNotFound, ErrConflict, ErrValidation. Message не содержит
+// внутренних подробностей, Details дополняет его данными для клиента.
+type Error struct {
+	Kind    error
+	Message string
+	Details any
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFound возвращает ошибку об отсутствующей записи.
+func NotFound(format string, args ...any) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict возвращает ошибку о нарушении связей между записями.
+func Conflict(format string, args ...any) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalid возвращает ошибку о данных, которые хранилище не может принять.
+func Invalid(format string, args ...any) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// RequireAffected возвращает notFound, если запрос не затронул ни одной строки.
+func RequireAffected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if n == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+// MissingActors возвращает ErrValidation со списком тех actorIDs, которых нет
+// в found, или nil, если найдены все.
+func MissingActors(actorIDs []int64, found map[int64]bool) error {
+	var missing []int64
+	for _, id := range actorIDs {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return &Error{
+		Kind:    ErrValidation,
+		Message: fmt.Sprintf("%d of the actors not found", len(missing)),
+		Details: missing,
+	}
+}
@@ -0,0 +1,12 @@
+package storage
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern возвращает шаблон LIKE, совпадающий со строками, которые
+// содержат fragment. Спецсимволы экранируются обратной косой чертой,
+// поэтому в запросе нужно указывать ESCAPE '\'.
+func ContainsPattern(fragment string) string {
+	return "%" + likeEscaper.Replace(fragment) + "%"
+}
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"sync"
+
+	"vk/internal/models"
+)
+
+// Storage хранит фильмы и актеров в памяти процесса.
+// Идентификаторы выдаются последовательно, как SERIAL в Postgres.
+type Storage struct {
+	mu sync.RWMutex
+
+	films  map[int64]models.Film
+	actors map[int64]models.Actor
+	// filmActors связывает фильм с его актерами: film_id -> set(actor_id)
+	filmActors map[int64]map[int64]struct{}
+
+	lastFilmID  int64
+	lastActorID int64
+}
+
+func New() *Storage {
+	return &Storage{
+		films:      make(map[int64]models.Film),
+		actors:     make(map[int64]models.Actor),
+		filmActors: make(map[int64]map[int64]struct{}),
+	}
+}
+
+// Close ничего не делает: данные живут только в памяти процесса.
+func (s *Storage) Close() error {
+	return nil
+}
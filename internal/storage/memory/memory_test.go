@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"vk/internal/storage"
+	"vk/internal/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return New()
+	})
+}
@@ -0,0 +1,681 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vk/internal/models"
+	"vk/internal/storage"
+)
+
+func (s *Storage) GetAllFilms(ctx context.Context, q storage.FilmQuery) (models.Page[models.Film], error) {
+	const op = "storage.memory.GetAllFilms"
+	q = q.Normalize()
+
+	less, err := filmLess(q)
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cursor, err := storage.DecodeCursor(q.Cursor, q.SortBy, q.Order)
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+	pivot, err := cursorFilm(q.SortBy, cursor)
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	films := make([]models.Film, 0, len(s.films))
+	for _, id := range sortedKeys(s.films) {
+		if _, ok := s.filmActors[id][q.ActorID]; q.ActorID != 0 && !ok {
+			continue
+		}
+		films = append(films, s.films[id])
+	}
+	sort.SliceStable(films, func(i, j int) bool {
+		return less(films[i], films[j])
+	})
+
+	position := func(film models.Film) int {
+		switch {
+		case less(film, pivot):
+			return -1
+		case less(pivot, film):
+			return 1
+		default:
+			return 0
+		}
+	}
+	key := func(film models.Film) storage.Keyed[models.Film] {
+		return storage.Keyed[models.Film]{Item: film, Key: filmSortValue(q.SortBy, film), ID: film.ID}
+	}
+
+	rows := paginate(films, q.PageQuery, cursor, position, key)
+
+	return storage.MakePage(rows, q.PageQuery, cursor, len(films)), nil
+}
+
+// filmLess повторяет ORDER BY из SQL-хранилищ: пустые и нераспознанные
+// значения в конце, при равенстве порядок по id.
+func filmLess(q storage.FilmQuery) (func(a, b models.Film) bool, error) {
+	var compare func(a, b models.Film) (int, bool)
+	switch q.SortBy {
+	case storage.SortByName:
+		compare = func(a, b models.Film) (int, bool) {
+			return strings.Compare(a.Name, b.Name), true
+		}
+	case storage.SortByRating:
+		compare = func(a, b models.Film) (int, bool) {
+			return a.Rating - b.Rating, true
+		}
+	case storage.SortByRelease:
+		compare = compareReleases
+	default:
+		return nil, fmt.Errorf("unknown sort field %q", q.SortBy)
+	}
+
+	var desc bool
+	switch q.Order {
+	case storage.OrderAsc:
+	case storage.OrderDesc:
+		desc = true
+	default:
+		return nil, fmt.Errorf("unknown sort order %q", q.Order)
+	}
+
+	return func(a, b models.Film) bool {
+		c, ok := compare(a, b)
+		if !ok {
+			// одно из значений пустое: пустые всегда после заполненных
+			return c < 0
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	}, nil
+}
+
+// filmSortValue возвращает значение поля сортировки, которое попадет в курсор.
+func filmSortValue(sortBy string, film models.Film) string {
+	switch sortBy {
+	case storage.SortByName:
+		return film.Name
+	case storage.SortByRating:
+		return strconv.Itoa(film.Rating)
+	default:
+		return film.Release.String()
+	}
+}
+
+// cursorFilm восстанавливает из курсора фильм, с которым сравниваются
+// остальные, чтобы найти начало страницы.
+func cursorFilm(sortBy string, cursor storage.Cursor) (models.Film, error) {
+	film := models.Film{ID: cursor.ID}
+	switch sortBy {
+	case storage.SortByName:
+		film.Name = cursor.Key
+	case storage.SortByRating:
+		if cursor.Key != "" {
+			rating, err := strconv.Atoi(cursor.Key)
+			if err != nil {
+				return models.Film{}, storage.ErrInvalidCursor
+			}
+			film.Rating = rating
+		}
+	default:
+		if cursor.Key != "" {
+			release, err := models.ParseDate(cursor.Key)
+			if err != nil {
+				return models.Film{}, storage.ErrInvalidCursor
+			}
+			film.Release = release
+		}
+	}
+
+	return film, nil
+}
+
+// compareReleases сравнивает даты выхода. Если хотя бы одна дата пустая,
+// второй результат false, а первый ставит пустую дату после заполненной.
+func compareReleases(a, b models.Film) (int, bool) {
+	switch {
+	case a.Release.IsZero() && b.Release.IsZero():
+		return 0, true
+	case a.Release.IsZero():
+		return 1, false
+	case b.Release.IsZero():
+		return -1, false
+	}
+
+	return a.Release.Compare(b.Release), true
+}
+
+func (s *Storage) SearchFilms(ctx context.Context, fragment string, q storage.PageQuery) (models.Page[models.Film], error) {
+	const op = "storage.memory.SearchFilms"
+	q = q.Normalize()
+
+	cursor, err := storage.DecodeCursor(q.Cursor, "", "")
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fragment = strings.ToLower(fragment)
+	contains := func(name string) bool {
+		return strings.Contains(strings.ToLower(name), fragment)
+	}
+
+	var ids []int64
+	for _, id := range sortedKeys(s.films) {
+		if contains(s.films[id].Name) {
+			ids = append(ids, id)
+			continue
+		}
+		for actorID := range s.filmActors[id] {
+			if contains(s.actors[actorID].Name) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+
+	rows := paginate(ids, q, cursor, func(id int64) int { return cmp.Compare(id, cursor.ID) },
+		func(id int64) storage.Keyed[models.Film] {
+			return storage.Keyed[models.Film]{Item: s.films[id], ID: id}
+		})
+
+	return storage.MakePage(rows, q, cursor, len(ids)), nil
+}
+
+func (s *Storage) GetAllActors(ctx context.Context, q storage.PageQuery) (models.Page[models.Actor], error) {
+	const op = "storage.memory.GetAllActors"
+	q = q.Normalize()
+
+	cursor, err := storage.DecodeCursor(q.Cursor, "", "")
+	if err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := sortedKeys(s.actors)
+	rows := paginate(ids, q, cursor, func(id int64) int { return cmp.Compare(id, cursor.ID) }, s.keyedActor)
+
+	return storage.MakePage(rows, q, cursor, len(ids)), nil
+}
+
+// keyedActor возвращает актера вместе с данными для курсора.
+// Вызывающий должен держать блокировку.
+func (s *Storage) keyedActor(id int64) storage.Keyed[models.Actor] {
+	return storage.Keyed[models.Actor]{Item: s.actors[id], ID: id}
+}
+
+func (s *Storage) AddFilm(ctx context.Context, film models.CreateFilm) (models.FilmWithActors, error) {
+	const op = "storage.memory.AddFilm"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	actorIDs, err := s.resolveActors(film.Actors)
+	if err != nil {
+		return models.FilmWithActors{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.lastFilmID++
+	filmID := s.lastFilmID
+
+	newFilm := film.Film
+	newFilm.ID = filmID
+	s.films[filmID] = newFilm
+
+	cast := make(map[int64]struct{}, len(actorIDs))
+	for _, actorID := range actorIDs {
+		cast[actorID] = struct{}{}
+	}
+	s.filmActors[filmID] = cast
+
+	return models.FilmWithActors{Film: newFilm, Actors: s.filmCast(filmID)}, nil
+}
+
+// resolveActors находит или создает актеров из состава нового фильма
+// и возвращает их id без повторов в порядке ссылок. Новые актеры создаются,
+// только когда все ссылки на существующих проверены.
+// Вызывающий должен держать блокировку на запись.
+func (s *Storage) resolveActors(refs []models.ActorRef) ([]int64, error) {
+	resolved := make([]int64, len(refs))
+	found := make(map[int64]bool, len(refs))
+	var byID []int64
+	for i, ref := range refs {
+		switch {
+		case ref.New != nil:
+		case ref.Name != "":
+			actorID, err := s.actorIDByName(ref.Name)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = actorID
+		default:
+			_, found[ref.ID] = s.actors[ref.ID]
+			byID = append(byID, ref.ID)
+			resolved[i] = ref.ID
+		}
+	}
+	if err := storage.MissingActors(byID, found); err != nil {
+		return nil, err
+	}
+
+	actorIDs := make([]int64, 0, len(refs))
+	seen := make(map[int64]bool, len(refs))
+	for i, ref := range refs {
+		actorID := resolved[i]
+		if ref.New != nil {
+			actorID = s.insertActor(*ref.New)
+		}
+
+		if !seen[actorID] {
+			seen[actorID] = true
+			actorIDs = append(actorIDs, actorID)
+		}
+	}
+
+	return actorIDs, nil
+}
+
+// actorIDByName возвращает id единственного актера с таким именем. Если их
+// несколько, ошибка перечисляет их в Details, чтобы клиент выбрал по ID.
+// Вызывающий должен держать блокировку.
+func (s *Storage) actorIDByName(name string) (int64, error) {
+	var candidates []models.Actor
+	var actorID int64
+	for _, id := range sortedKeys(s.actors) {
+		if s.actors[id].Name == name {
+			candidates = append(candidates, s.actors[id])
+			actorID = id
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return 0, storage.Invalid("actor %q not found", name)
+	case 1:
+		return actorID, nil
+	}
+
+	return 0, &storage.Error{
+		Kind:    storage.ErrValidation,
+		Message: fmt.Sprintf("actor name %q matches %d actors, use an actor ID", name, len(candidates)),
+		Details: candidates,
+	}
+}
+
+func (s *Storage) DeleteFilm(ctx context.Context, id int64) error {
+	const op = "storage.memory.DeleteFilm"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.films[id]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.NotFound("film %d not found", id))
+	}
+
+	delete(s.films, id)
+	delete(s.filmActors, id)
+
+	return nil
+}
+
+func (s *Storage) FindFilm(ctx context.Context, id int64) (models.Film, error) {
+	const op = "storage.memory.FindFilm"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	film, ok := s.films[id]
+	if !ok {
+		return models.Film{}, fmt.Errorf("%s: %w", op, storage.NotFound("film %d not found", id))
+	}
+
+	return film, nil
+}
+
+func (s *Storage) UpdateFilm(ctx context.Context, id int64, patch models.FilmPatch) (models.Film, error) {
+	const op = "storage.memory.UpdateFilm"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	film, ok := s.films[id]
+	if !ok {
+		return models.Film{}, fmt.Errorf("%s: %w", op, storage.NotFound("film %d not found", id))
+	}
+
+	if patch.Name.Set {
+		film.Name = patch.Name.Value
+	}
+	if patch.Description.Set {
+		film.Description = patch.Description.Value
+	}
+	if patch.Rating.Set {
+		film.Rating = patch.Rating.Value
+	}
+	if patch.Release.Set {
+		film.Release = patch.Release.Value
+	}
+
+	s.films[id] = film
+
+	return film, nil
+}
+
+func (s *Storage) FindActor(ctx context.Context, id int64) (models.Actor, error) {
+	const op = "storage.memory.FindActor"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	actor, ok := s.actors[id]
+	if !ok {
+		return models.Actor{}, fmt.Errorf("%s: %w", op, storage.NotFound("actor %d not found", id))
+	}
+
+	return actor, nil
+}
+
+func (s *Storage) AddActor(ctx context.Context, actor models.Actor) (models.Actor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.insertActor(actor)
+
+	return s.actors[id], nil
+}
+
+// insertActor добавляет актера и возвращает его id.
+// Вызывающий должен держать блокировку на запись.
+func (s *Storage) insertActor(actor models.Actor) int64 {
+	s.lastActorID++
+	actor.ID = s.lastActorID
+	s.actors[s.lastActorID] = actor
+
+	return s.lastActorID
+}
+
+func (s *Storage) DeleteActor(ctx context.Context, id int64, cascade bool) error {
+	const op = "storage.memory.DeleteActor"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.actors[id]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.NotFound("actor %d not found", id))
+	}
+
+	var filmIDs []int64
+	for _, filmID := range sortedKeys(s.filmActors) {
+		if _, ok := s.filmActors[filmID][id]; ok {
+			filmIDs = append(filmIDs, filmID)
+		}
+	}
+
+	if len(filmIDs) > 0 && !cascade {
+		films := make([]models.Film, 0, len(filmIDs))
+		for _, filmID := range filmIDs {
+			films = append(films, s.films[filmID])
+		}
+
+		return fmt.Errorf("%s: %w", op, &storage.Error{
+			Kind:    storage.ErrConflict,
+			Message: fmt.Sprintf("actor %d is in the cast of %d films", id, len(films)),
+			Details: films,
+		})
+	}
+
+	for _, filmID := range filmIDs {
+		delete(s.filmActors[filmID], id)
+	}
+	delete(s.actors, id)
+
+	return nil
+}
+
+func (s *Storage) UpdateActor(ctx context.Context, id int64, patch models.ActorPatch) (models.Actor, error) {
+	const op = "storage.memory.UpdateActor"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	actor, ok := s.actors[id]
+	if !ok {
+		return models.Actor{}, fmt.Errorf("%s: %w", op, storage.NotFound("actor %d not found", id))
+	}
+
+	if patch.Name.Set {
+		actor.Name = patch.Name.Value
+	}
+	if patch.Sex.Set {
+		actor.Sex = patch.Sex.Value
+	}
+	if patch.Birthday.Set {
+		actor.Birthday = patch.Birthday.Value
+	}
+
+	s.actors[id] = actor
+
+	return actor, nil
+}
+
+func (s *Storage) GetActorsByFilmID(ctx context.Context, filmID int64, q storage.PageQuery) (models.Page[models.Actor], error) {
+	const op = "storage.memory.GetActorsByFilmID"
+	q = q.Normalize()
+
+	cursor, err := storage.DecodeCursor(q.Cursor, "", "")
+	if err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.films[filmID]; !ok {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, storage.NotFound("film %d not found", filmID))
+	}
+
+	ids := sortedKeys(s.filmActors[filmID])
+	rows := paginate(ids, q, cursor, func(id int64) int { return cmp.Compare(id, cursor.ID) }, s.keyedActor)
+
+	return storage.MakePage(rows, q, cursor, len(ids)), nil
+}
+
+// paginate выбирает из отсортированного items до q.Limit+1 записей
+// в направлении курсора так же, как SQL-хранилища: после курсора
+// по порядку, перед ним - в обратном порядке. position сравнивает
+// запись с курсором и возвращает отрицательное число, если запись перед ним.
+func paginate[T, R any](items []T, q storage.PageQuery, cursor storage.Cursor,
+	position func(T) int, keyed func(T) storage.Keyed[R]) []storage.Keyed[R] {
+	var selected []T
+	switch {
+	case q.Cursor == "":
+		selected = items
+	case cursor.Before:
+		for _, item := range items {
+			if position(item) < 0 {
+				selected = append(selected, item)
+			}
+		}
+		slices.Reverse(selected)
+	default:
+		for _, item := range items {
+			if position(item) > 0 {
+				selected = append(selected, item)
+			}
+		}
+	}
+
+	if len(selected) > q.Limit+1 {
+		selected = selected[:q.Limit+1]
+	}
+
+	rows := make([]storage.Keyed[R], 0, len(selected))
+	for _, item := range selected {
+		rows = append(rows, keyed(item))
+	}
+
+	return rows
+}
+
+// sortedKeys возвращает ключи в порядке возрастания, чтобы выдача
+// не зависела от порядка обхода map.
+func sortedKeys[V any](m map[int64]V) []int64 {
+	keys := make([]int64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+func (s *Storage) GetFilmsByActorID(ctx context.Context, actorID int64, q storage.FilmQuery) (models.Page[models.Film], error) {
+	const op = "storage.memory.GetFilmsByActorID"
+
+	s.mu.RLock()
+	_, ok := s.actors[actorID]
+	s.mu.RUnlock()
+	if !ok {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, storage.NotFound("actor %d not found", actorID))
+	}
+
+	q.ActorID = actorID
+	return s.GetAllFilms(ctx, q)
+}
+
+func (s *Storage) GetFilmsByActorIDs(ctx context.Context, actorIDs []int64) (map[int64][]models.Film, error) {
+	const op = "storage.memory.GetFilmsByActorIDs"
+
+	less, err := filmLess(storage.FilmQuery{SortBy: storage.SortByRelease}.Normalize())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wanted := make(map[int64]bool, len(actorIDs))
+	for _, id := range actorIDs {
+		wanted[id] = true
+	}
+
+	films := make(map[int64][]models.Film, len(actorIDs))
+	for _, filmID := range sortedKeys(s.filmActors) {
+		for actorID := range s.filmActors[filmID] {
+			if wanted[actorID] {
+				films[actorID] = append(films[actorID], s.films[filmID])
+			}
+		}
+	}
+	for _, list := range films {
+		sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+	}
+
+	return films, nil
+}
+
+func (s *Storage) AddFilmActors(ctx context.Context, filmID int64, actorIDs []int64) ([]models.Actor, error) {
+	const op = "storage.memory.AddFilmActors"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.changeCast(filmID, actorIDs, false); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.filmCast(filmID), nil
+}
+
+func (s *Storage) ReplaceFilmActors(ctx context.Context, filmID int64, actorIDs []int64) ([]models.Actor, error) {
+	const op = "storage.memory.ReplaceFilmActors"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.changeCast(filmID, actorIDs, true); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.filmCast(filmID), nil
+}
+
+// changeCast добавляет актеров в состав фильма, а с replace сначала
+// убирает из него всех остальных. Состав меняется, только если все
+// проверки прошли. Вызывающий должен держать блокировку на запись.
+func (s *Storage) changeCast(filmID int64, actorIDs []int64, replace bool) error {
+	if _, ok := s.films[filmID]; !ok {
+		return storage.NotFound("film %d not found", filmID)
+	}
+
+	found := make(map[int64]bool, len(actorIDs))
+	for _, id := range actorIDs {
+		_, found[id] = s.actors[id]
+	}
+	if err := storage.MissingActors(actorIDs, found); err != nil {
+		return err
+	}
+
+	cast := s.filmActors[filmID]
+	if replace || cast == nil {
+		cast = make(map[int64]struct{}, len(actorIDs))
+		s.filmActors[filmID] = cast
+	}
+	for _, id := range actorIDs {
+		cast[id] = struct{}{}
+	}
+
+	return nil
+}
+
+func (s *Storage) RemoveFilmActor(ctx context.Context, filmID, actorID int64) ([]models.Actor, error) {
+	const op = "storage.memory.RemoveFilmActor"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.films[filmID]; !ok {
+		return nil, fmt.Errorf("%s: %w", op, storage.NotFound("film %d not found", filmID))
+	}
+	if _, ok := s.filmActors[filmID][actorID]; !ok {
+		return nil, fmt.Errorf("%s: %w", op, storage.NotFound("actor %d is not in the cast of film %d", actorID, filmID))
+	}
+
+	delete(s.filmActors[filmID], actorID)
+
+	return s.filmCast(filmID), nil
+}
+
+// filmCast возвращает весь состав фильма по возрастанию id актеров.
+// Вызывающий должен держать блокировку.
+func (s *Storage) filmCast(filmID int64) []models.Actor {
+	ids := sortedKeys(s.filmActors[filmID])
+	cast := make([]models.Actor, 0, len(ids))
+	for _, id := range ids {
+		cast = append(cast, s.actors[id])
+	}
+
+	return cast
+}
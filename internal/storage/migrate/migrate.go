@@ -0,0 +1,239 @@
+// Package migrate применяет к базе данных версионированные SQL-миграции.
+//
+// Миграции лежат в файлах вида 0001_init.up.sql и 0001_init.down.sql,
+// номер задает порядок применения. Примененные версии записываются
+// в таблицу schema_migrations.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrSchemaOutdated = errors.New("database schema is out of date")
+	ErrNoMigrations   = errors.New("no migrations to roll back")
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New читает миграции из корня fsys.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	const op = "storage.migrate.New"
+
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		base := strings.TrimSuffix(path.Base(file), ".sql")
+
+		var up bool
+		switch {
+		case strings.HasSuffix(base, ".up"):
+			up = true
+			base = strings.TrimSuffix(base, ".up")
+		case strings.HasSuffix(base, ".down"):
+			base = strings.TrimSuffix(base, ".down")
+		default:
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql suffix", file)
+		}
+
+		rawVersion, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected <version>_<name> file name", file)
+		}
+		version, err := strconv.Atoi(rawVersion)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", file, rawVersion)
+		}
+
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, name)
+		}
+		if up {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Latest возвращает версию последней известной миграции.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version возвращает последнюю примененную к базе версию.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	const op = "storage.migrate.Version"
+
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var version int
+	err := m.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, nil
+}
+
+// Check возвращает ErrSchemaOutdated, если в базе применены не все миграции.
+func (m *Migrator) Check(ctx context.Context) error {
+	const op = "storage.migrate.Check"
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version < m.Latest() {
+		return fmt.Errorf("%s: %w: version %d, latest %d", op, ErrSchemaOutdated, version, m.Latest())
+	}
+
+	return nil
+}
+
+// Up применяет все еще не примененные миграции и возвращает их.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	const op = "storage.migrate.Up"
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			continue
+		}
+
+		err := m.exec(ctx, migration.Up,
+			"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+		if err != nil {
+			return applied, fmt.Errorf("%s: migration %d_%s: %w", op, migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Down откатывает последнюю примененную миграцию.
+func (m *Migrator) Down(ctx context.Context) (Migration, error) {
+	const op = "storage.migrate.Down"
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+	if version == 0 {
+		return Migration{}, fmt.Errorf("%s: %w", op, ErrNoMigrations)
+	}
+
+	idx := sort.Search(len(m.migrations), func(i int) bool {
+		return m.migrations[i].Version >= version
+	})
+	if idx == len(m.migrations) || m.migrations[idx].Version != version {
+		return Migration{}, fmt.Errorf("%s: applied version %d is unknown", op, version)
+	}
+	migration := m.migrations[idx]
+	if migration.Down == "" {
+		return Migration{}, fmt.Errorf("%s: migration %d_%s has no down file", op, migration.Version, migration.Name)
+	}
+
+	err = m.exec(ctx, migration.Down,
+		"DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+	if err != nil {
+		return Migration{}, fmt.Errorf("%s: migration %d_%s: %w", op, migration.Version, migration.Name, err)
+	}
+
+	return migration, nil
+}
+
+// exec выполняет тело миграции и запись в schema_migrations в одной транзакции.
+func (m *Migrator) exec(ctx context.Context, body, query string, args ...any) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`)
+
+	return err
+}
@@ -0,0 +1,160 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"0010_b.up.sql":   file("SELECT 1"),
+				"0002_a.up.sql":   file("SELECT 1"),
+				"0002_a.down.sql": file("SELECT 1"),
+			},
+			want: []int{2, 10},
+		},
+		{name: "empty", fsys: fstest.MapFS{}},
+		{name: "no suffix", fsys: fstest.MapFS{"0001_a.sql": file("")}, wantErr: true},
+		{name: "no name", fsys: fstest.MapFS{"0001.up.sql": file("")}, wantErr: true},
+		{name: "bad version", fsys: fstest.MapFS{"x_a.up.sql": file("")}, wantErr: true},
+		{name: "zero version", fsys: fstest.MapFS{"0000_a.up.sql": file("")}, wantErr: true},
+		{name: "only down", fsys: fstest.MapFS{"0001_a.down.sql": file("SELECT 1")}, wantErr: true},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql":   file("SELECT 1"),
+				"0001_b.down.sql": file("SELECT 1"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := load(tt.fsys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []int
+			for _, m := range migrations {
+				got = append(got, m.Version)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("load() versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	m, err := New(db, fstest.MapFS{
+		"0001_films.up.sql":     file("CREATE TABLE films (id INTEGER PRIMARY KEY)"),
+		"0001_films.down.sql":   file("DROP TABLE films"),
+		"0002_rating.up.sql":    file("ALTER TABLE films ADD COLUMN rating INTEGER"),
+		"0002_rating.down.sql":  file("ALTER TABLE films DROP COLUMN rating"),
+		"0003_broken.up.sql":    file("ALTER TABLE missing ADD COLUMN x INTEGER"),
+		"0003_broken.down.sql":  file("SELECT 1"),
+		"0004_unreached.up.sql": file("SELECT 1"),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if m.Latest() != 4 {
+		t.Fatalf("Latest() = %d, want 4", m.Latest())
+	}
+
+	if err := m.Check(ctx); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("Check() on empty database error = %v, want ErrSchemaOutdated", err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err == nil {
+		t.Fatal("Up() with broken migration error = nil")
+	}
+	if len(applied) != 2 {
+		t.Fatalf("Up() applied %d migrations, want 2", len(applied))
+	}
+	if version, err := m.Version(ctx); err != nil || version != 2 {
+		t.Fatalf("Version() = %d, %v, want 2", version, err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO films (id, rating) VALUES (1, 5)"); err != nil {
+		t.Fatalf("insert after Up() error = %v", err)
+	}
+
+	down, err := m.Down(ctx)
+	if err != nil || down.Version != 2 {
+		t.Fatalf("Down() = %d, %v, want version 2", down.Version, err)
+	}
+	if _, err := db.ExecContext(ctx, "SELECT rating FROM films"); err == nil {
+		t.Fatal("rating column still exists after Down()")
+	}
+
+	if _, err := m.Down(ctx); err != nil {
+		t.Fatalf("second Down() error = %v", err)
+	}
+	if _, err := m.Down(ctx); !errors.Is(err, ErrNoMigrations) {
+		t.Fatalf("Down() on empty database error = %v, want ErrNoMigrations", err)
+	}
+	if version, err := m.Version(ctx); err != nil || version != 0 {
+		t.Fatalf("Version() = %d, %v, want 0", version, err)
+	}
+}
+
+func TestCheckUpToDate(t *testing.T) {
+	ctx := context.Background()
+
+	m, err := New(openDB(t), fstest.MapFS{
+		"0001_films.up.sql":   file("CREATE TABLE films (id INTEGER PRIMARY KEY)"),
+		"0001_films.down.sql": file("DROP TABLE films"),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("repeated Up() = %v, %v, want nothing applied", applied, err)
+	}
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"vk/internal/models"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageQuery задает страницу списка: не больше Limit записей после или
+// до записи, на которую указывает Cursor. Пустой Cursor означает первую страницу.
+type PageQuery struct {
+	Limit  int
+	Cursor string
+}
+
+// Normalize ограничивает Limit допустимым диапазоном.
+func (q PageQuery) Normalize() PageQuery {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+
+	return q
+}
+
+// Cursor указывает на запись, с которой продолжается выдача. Key - значение
+// поля сортировки этой записи, ID разрешает равенство ключей. SortBy и Order
+// привязывают курсор к порядку, в котором он был выдан.
+type Cursor struct {
+	SortBy string `json:"s,omitempty"`
+	Order  string `json:"o,omitempty"`
+	Key    string `json:"k,omitempty"`
+	ID     int64  `json:"i"`
+	// Before означает страницу перед записью, а не после нее.
+	Before bool `json:"b,omitempty"`
+}
+
+// Encode возвращает непрозрачное для клиента строковое представление курсора.
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor разбирает курсор и проверяет, что он выдан для того же порядка.
+// Для пустой строки возвращается курсор на начало списка.
+func DecodeCursor(s, sortBy, order string) (Cursor, error) {
+	if s == "" {
+		return Cursor{SortBy: sortBy, Order: order}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if c.SortBy != sortBy || c.Order != order {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Keyed - запись вместе со значениями, из которых строится курсор на нее.
+type Keyed[T any] struct {
+	Item T
+	Key  string
+	ID   int64
+}
+
+// MakePage собирает страницу из записей, выбранных хранилищем в направлении
+// курсора: до q.Limit+1 записей, для Before-курсора в обратном порядке.
+// Лишняя запись только сообщает, что в этом направлении есть еще страница.
+func MakePage[T any](rows []Keyed[T], q PageQuery, cursor Cursor, total int) models.Page[T] {
+	more := len(rows) > q.Limit
+	if more {
+		rows = rows[:q.Limit]
+	}
+	if cursor.Before {
+		slices.Reverse(rows)
+	}
+
+	page := models.Page[T]{Items: make([]T, 0, len(rows)), Total: total}
+	for _, row := range rows {
+		page.Items = append(page.Items, row.Item)
+	}
+	if len(rows) == 0 {
+		return page
+	}
+
+	// Раз курсор указывал на запись, в противоположном от него
+	// направлении она и все, что перед ней, еще остались.
+	hasCursor := q.Cursor != ""
+	hasNext := more && !cursor.Before || hasCursor && cursor.Before
+	hasPrev := more && cursor.Before || hasCursor && !cursor.Before
+
+	first, last := rows[0], rows[len(rows)-1]
+	if hasNext {
+		page.NextCursor = Cursor{SortBy: cursor.SortBy, Order: cursor.Order, Key: last.Key, ID: last.ID}.Encode()
+	}
+	if hasPrev {
+		page.PrevCursor = Cursor{SortBy: cursor.SortBy, Order: cursor.Order, Key: first.Key, ID: first.ID, Before: true}.Encode()
+	}
+
+	return page
+}
@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPageQueryNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "zero", limit: 0, want: DefaultLimit},
+		{name: "negative", limit: -5, want: DefaultLimit},
+		{name: "in range", limit: 7, want: 7},
+		{name: "max", limit: MaxLimit, want: MaxLimit},
+		{name: "above max", limit: MaxLimit + 1, want: MaxLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PageQuery{Limit: tt.limit}.Normalize().Limit
+			if got != tt.want {
+				t.Errorf("Normalize().Limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	valid := Cursor{SortBy: SortByName, Order: "asc", Key: "Matrix", ID: 3, Before: true}
+
+	tests := []struct {
+		name    string
+		raw     string
+		sortBy  string
+		order   string
+		want    Cursor
+		wantErr bool
+	}{
+		{name: "empty", raw: "", sortBy: SortByName, order: "asc", want: Cursor{SortBy: SortByName, Order: "asc"}},
+		{name: "round trip", raw: valid.Encode(), sortBy: SortByName, order: "asc", want: valid},
+		{name: "not base64", raw: "!!!", sortBy: SortByName, order: "asc", wantErr: true},
+		{name: "not json", raw: "bm90IGpzb24", sortBy: SortByName, order: "asc", wantErr: true},
+		{name: "other sort", raw: valid.Encode(), sortBy: SortByRating, order: "asc", wantErr: true},
+		{name: "other order", raw: valid.Encode(), sortBy: SortByName, order: "desc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.raw, tt.sortBy, tt.order)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCursor) {
+					t.Fatalf("DecodeCursor() error = %v, want ErrInvalidCursor", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DecodeCursor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakePage(t *testing.T) {
+	rows := func(ids ...int64) []Keyed[int64] {
+		keyed := make([]Keyed[int64], 0, len(ids))
+		for _, id := range ids {
+			keyed = append(keyed, Keyed[int64]{Item: id, ID: id})
+		}
+		return keyed
+	}
+	after := Cursor{ID: 2}.Encode()
+	before := Cursor{ID: 5, Before: true}.Encode()
+
+	tests := []struct {
+		name     string
+		rows     []Keyed[int64]
+		cursor   string
+		want     []int64
+		wantNext *Cursor
+		wantPrev *Cursor
+	}{
+		{
+			name: "empty",
+			rows: nil,
+			want: []int64{},
+		},
+		{
+			name: "single first page",
+			rows: rows(1, 2),
+			want: []int64{1, 2},
+		},
+		{
+			name:     "first page with more",
+			rows:     rows(1, 2, 3),
+			want:     []int64{1, 2},
+			wantNext: &Cursor{ID: 2},
+		},
+		{
+			name:     "middle page after cursor",
+			rows:     rows(3, 4, 5),
+			cursor:   after,
+			want:     []int64{3, 4},
+			wantNext: &Cursor{ID: 4},
+			wantPrev: &Cursor{ID: 3, Before: true},
+		},
+		{
+			name:     "last page after cursor",
+			rows:     rows(3),
+			cursor:   after,
+			want:     []int64{3},
+			wantPrev: &Cursor{ID: 3, Before: true},
+		},
+		{
+			name:     "page before cursor with more",
+			rows:     rows(4, 3, 2),
+			cursor:   before,
+			want:     []int64{3, 4},
+			wantNext: &Cursor{ID: 4},
+			wantPrev: &Cursor{ID: 3, Before: true},
+		},
+		{
+			name:     "first page before cursor",
+			rows:     rows(4, 3),
+			cursor:   before,
+			want:     []int64{3, 4},
+			wantNext: &Cursor{ID: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := PageQuery{Limit: 2, Cursor: tt.cursor}
+			cursor, err := DecodeCursor(tt.cursor, "", "")
+			if err != nil {
+				t.Fatalf("DecodeCursor() error = %v", err)
+			}
+
+			page := MakePage(tt.rows, q, cursor, 10)
+			if !slices.Equal(page.Items, tt.want) {
+				t.Errorf("Items = %v, want %v", page.Items, tt.want)
+			}
+			if page.Total != 10 {
+				t.Errorf("Total = %d, want 10", page.Total)
+			}
+			checkCursor(t, "NextCursor", page.NextCursor, tt.wantNext)
+			checkCursor(t, "PrevCursor", page.PrevCursor, tt.wantPrev)
+		})
+	}
+}
+
+func checkCursor(t *testing.T, name, raw string, want *Cursor) {
+	t.Helper()
+
+	if want == nil {
+		if raw != "" {
+			t.Errorf("%s = %q, want none", name, raw)
+		}
+		return
+	}
+
+	got, err := DecodeCursor(raw, "", "")
+	if err != nil || raw == "" {
+		t.Fatalf("%s = %q, error = %v", name, raw, err)
+	}
+	if got != *want {
+		t.Errorf("%s = %+v, want %+v", name, got, *want)
+	}
+}
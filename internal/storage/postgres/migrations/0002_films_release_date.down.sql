@@ -0,0 +1 @@
+ALTER TABLE films ALTER COLUMN release TYPE TEXT USING to_char(release, 'YYYY-MM-DD');
@@ -0,0 +1,29 @@
+-- Перед сменой типа перечисляем фильмы, release которых не разбирается как дата,
+-- чтобы их можно было исправить вручную, а не потерять. Регулярное выражение
+-- пропустило бы несуществующие даты вроде 2001-02-30, поэтому каждое значение
+-- приводится к date в своем блоке с перехватом ошибки и сравнивается с исходным.
+DO $$
+DECLARE
+	r RECORD;
+	valid BOOLEAN;
+	bad TEXT[] := '{}';
+BEGIN
+	FOR r IN SELECT id, release FROM films WHERE release IS NOT NULL AND release <> '' ORDER BY id LOOP
+		BEGIN
+			valid := to_char(r.release::date, 'YYYY-MM-DD') = r.release;
+		EXCEPTION WHEN data_exception THEN
+			valid := false;
+		END;
+
+		IF NOT valid THEN
+			bad := array_append(bad, format('%s (%L)', r.id, r.release));
+		END IF;
+	END LOOP;
+
+	IF cardinality(bad) > 0 THEN
+		RAISE EXCEPTION 'films with release not in YYYY-MM-DD format: %', array_to_string(bad, ', ');
+	END IF;
+END
+$$;
+
+ALTER TABLE films ALTER COLUMN release TYPE DATE USING NULLIF(release, '')::date;
@@ -0,0 +1,4 @@
+ALTER TABLE film_actors
+	DROP CONSTRAINT film_actors_film_id_fkey,
+	ADD CONSTRAINT film_actors_film_id_fkey
+		FOREIGN KEY (film_id) REFERENCES films(id);
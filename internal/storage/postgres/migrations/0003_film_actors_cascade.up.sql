@@ -0,0 +1,6 @@
+-- Удаление фильма удаляет и связи с его актерами.
+-- Связи актера так не удаляются: DeleteActor решает это сам.
+ALTER TABLE film_actors
+	DROP CONSTRAINT film_actors_film_id_fkey,
+	ADD CONSTRAINT film_actors_film_id_fkey
+		FOREIGN KEY (film_id) REFERENCES films(id) ON DELETE CASCADE;
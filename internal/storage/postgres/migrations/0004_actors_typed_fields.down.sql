@@ -0,0 +1,3 @@
+ALTER TABLE actors DROP CONSTRAINT actors_sex_check;
+UPDATE actors SET sex = '' WHERE sex IS NULL;
+ALTER TABLE actors ALTER COLUMN birthday TYPE TEXT USING COALESCE(to_char(birthday, 'YYYY-MM-DD'), '');
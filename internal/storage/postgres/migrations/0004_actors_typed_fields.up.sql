@@ -0,0 +1,40 @@
+-- Перед сменой типа перечисляем актеров, birthday или sex которых не разбираются,
+-- чтобы их можно было исправить вручную, а не потерять. Дату проверяет
+-- приведение к date, как в 0002_films_release_date.
+DO $$
+DECLARE
+	r RECORD;
+	valid BOOLEAN;
+	bad TEXT[] := '{}';
+BEGIN
+	FOR r IN SELECT id, birthday FROM actors WHERE birthday IS NOT NULL AND birthday <> '' ORDER BY id LOOP
+		BEGIN
+			valid := to_char(r.birthday::date, 'YYYY-MM-DD') = r.birthday;
+		EXCEPTION WHEN data_exception THEN
+			valid := false;
+		END;
+
+		IF NOT valid THEN
+			bad := array_append(bad, format('%s (%L)', r.id, r.birthday));
+		END IF;
+	END LOOP;
+
+	IF cardinality(bad) > 0 THEN
+		RAISE EXCEPTION 'actors with birthday not in YYYY-MM-DD format: %', array_to_string(bad, ', ');
+	END IF;
+
+	SELECT coalesce(array_agg(format('%s (%L)', id, sex) ORDER BY id), '{}') INTO bad
+	FROM actors
+	WHERE sex IS NOT NULL AND sex NOT IN ('', 'male', 'female');
+
+	IF cardinality(bad) > 0 THEN
+		RAISE EXCEPTION 'actors with sex other than male or female: %', array_to_string(bad, ', ');
+	END IF;
+END
+$$;
+
+ALTER TABLE actors ALTER COLUMN birthday TYPE DATE USING NULLIF(birthday, '')::date;
+
+-- Неизвестный пол теперь NULL, а не пустая строка.
+UPDATE actors SET sex = NULL WHERE sex = '';
+ALTER TABLE actors ADD CONSTRAINT actors_sex_check CHECK (sex IN ('male', 'female'));
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"vk/internal/storage"
+	"vk/internal/storage/migrate"
+	"vk/internal/storage/sqlstore"
+
+	"github.com/lib/pq"
+)
+
+// foreignKeyViolation - код ошибки PostgreSQL при нарушении внешнего ключа.
+const foreignKeyViolation = "23503"
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// dialect - особенности запросов PostgreSQL для sqlstore.
+var dialect = sqlstore.Dialect{
+	Placeholder: storage.Dollar,
+	FilmSortKeys: map[string]sqlstore.SortKey{
+		storage.SortByName:    {Asc: "f.name", Desc: "f.name", Param: "?::text"},
+		storage.SortByRating:  {Asc: "COALESCE(f.rating, 2147483647)", Desc: "COALESCE(f.rating, -2147483648)", Param: "?::integer"},
+		storage.SortByRelease: {Asc: "COALESCE(f.release, 'infinity'::date)", Desc: "COALESCE(f.release, '-infinity'::date)", Param: "?::date"},
+	},
+	// ILIKE и lower без явной сортировки сравнивают регистр по LC_CTYPE базы,
+	// и с ctype C кириллица не приводится к нижнему регистру. Правило ICU
+	// "und-x-icu" не зависит от локали базы.
+	ILike: func(column string) string {
+		return `lower(` + column + ` COLLATE "und-x-icu") LIKE lower(?::text COLLATE "und-x-icu")`
+	},
+	AnyOf: func(column string, ids []int64) (string, []any) {
+		return column + " = ANY(?)", []any{pq.Array(ids)}
+	},
+	LockRow:               " FOR UPDATE",
+	IsForeignKeyViolation: isForeignKeyViolation,
+}
+
+type Storage struct {
+	*sqlstore.Store
+	db       *sql.DB
+	migrator *migrate.Migrator
+}
+
+// New открывает соединение с базой по строке подключения lib/pq
+// (например, "host=localhost user=postgres dbname=vk sslmode=disable").
+// Схему создают миграции, см. Migrator.
+func New(dsn string) (*Storage, error) {
+	const op = "storage.postgres.New"
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Storage{Store: sqlstore.New(db, dialect), db: db, migrator: migrator}, nil
+}
+
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+func (s *Storage) Migrator() *migrate.Migrator {
+	return s.migrator
+}
+
+func newMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(db, fsys)
+}
+
+// isForeignKeyViolation сообщает, что запрос нарушил внешний ключ.
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation
+}
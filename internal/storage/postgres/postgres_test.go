@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"vk/internal/models"
+	"vk/internal/storage"
+	"vk/internal/storage/migrate"
+	"vk/internal/storage/storagetest"
+)
+
+// dsnEnv задает строку подключения к пустой базе для тестов. Без нее
+// тесты PostgreSQL пропускаются. Миграции создают и удаляют в базе таблицы.
+const dsnEnv = "POSTGRES_TEST_DSN"
+
+func TestStorage(t *testing.T) {
+	s := open(t)
+
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		// каждая проверка начинает с пустых таблиц
+		migrateUp(t, s)
+		return s
+	})
+}
+
+// TestSearchCase проверяет поиск кириллицы в разном регистре при любой
+// LC_CTYPE тестовой базы, в том числе C.
+func TestSearchCase(t *testing.T) {
+	s := open(t)
+	migrateUp(t, s)
+	ctx := context.Background()
+
+	var ctype string
+	if err := s.db.QueryRowContext(ctx, "SHOW lc_ctype").Scan(&ctype); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("lc_ctype = %s", ctype)
+
+	if _, err := s.AddFilm(ctx, models.CreateFilm{Film: models.Film{Name: "Ёлки"}}); err != nil {
+		t.Fatalf("AddFilm() error = %v", err)
+	}
+	for _, fragment := range []string{"ёлки", "ЁЛКИ", "ёЛкИ"} {
+		page, err := s.SearchFilms(ctx, fragment, storage.PageQuery{})
+		if err != nil {
+			t.Fatalf("SearchFilms(%q) error = %v", fragment, err)
+		}
+		if len(page.Items) != 1 {
+			t.Errorf("SearchFilms(%q) = %+v, want Ёлки", fragment, page.Items)
+		}
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	s := open(t)
+
+	storagetest.RunMigrations(t, s, func(ctx context.Context, name string) (bool, error) {
+		var n int
+		err := s.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1",
+			name).Scan(&n)
+		return n > 0, err
+	})
+}
+
+// open подключается к базе из dsnEnv или пропускает тест.
+func open(t *testing.T) *Storage {
+	t.Helper()
+
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", dsnEnv)
+	}
+
+	s, err := New(dsn)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+
+	return s
+}
+
+// migrateUp применяет все миграции и откатывает их после теста.
+func migrateUp(t *testing.T, s *Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	if _, err := s.Migrator().Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	t.Cleanup(func() {
+		for {
+			_, err := s.Migrator().Down(ctx)
+			if errors.Is(err, migrate.ErrNoMigrations) {
+				return
+			}
+			if err != nil {
+				t.Fatalf("Down() error = %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS film_actors;
+DROP TABLE IF EXISTS actors;
+DROP TABLE IF EXISTS films;
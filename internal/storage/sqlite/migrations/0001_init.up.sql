@@ -0,0 +1,23 @@
+-- IF NOT EXISTS позволяет применить миграцию к базе, созданной до появления миграций.
+CREATE TABLE IF NOT EXISTS films (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	description TEXT,
+	rating INTEGER,
+	release TEXT
+);
+
+CREATE TABLE IF NOT EXISTS actors (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	sex TEXT,
+	birthday TEXT
+);
+
+CREATE TABLE IF NOT EXISTS film_actors (
+	film_id INTEGER,
+	actor_id INTEGER,
+	PRIMARY KEY (film_id, actor_id),
+	FOREIGN KEY (film_id) REFERENCES films(id),
+	FOREIGN KEY (actor_id) REFERENCES actors(id)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE film_actors_old (
+	film_id INTEGER,
+	actor_id INTEGER,
+	PRIMARY KEY (film_id, actor_id),
+	FOREIGN KEY (film_id) REFERENCES films(id),
+	FOREIGN KEY (actor_id) REFERENCES actors(id)
+);
+
+INSERT INTO film_actors_old (film_id, actor_id) SELECT film_id, actor_id FROM film_actors;
+DROP TABLE film_actors;
+ALTER TABLE film_actors_old RENAME TO film_actors;
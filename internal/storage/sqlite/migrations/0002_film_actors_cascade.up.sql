@@ -0,0 +1,14 @@
+-- Удаление фильма удаляет и связи с его актерами.
+-- Связи актера так не удаляются: DeleteActor решает это сам.
+-- SQLite не меняет внешние ключи существующей таблицы, поэтому она пересоздается.
+CREATE TABLE film_actors_new (
+	film_id INTEGER,
+	actor_id INTEGER,
+	PRIMARY KEY (film_id, actor_id),
+	FOREIGN KEY (film_id) REFERENCES films(id) ON DELETE CASCADE,
+	FOREIGN KEY (actor_id) REFERENCES actors(id)
+);
+
+INSERT INTO film_actors_new (film_id, actor_id) SELECT film_id, actor_id FROM film_actors;
+DROP TABLE film_actors;
+ALTER TABLE film_actors_new RENAME TO film_actors;
@@ -0,0 +1,10 @@
+DROP TRIGGER actors_sex_update;
+DROP TRIGGER actors_sex_insert;
+DROP TRIGGER actors_birthday_update;
+DROP TRIGGER actors_birthday_insert;
+DROP TRIGGER films_release_update;
+DROP TRIGGER films_release_insert;
+
+UPDATE films SET release = '' WHERE release IS NULL;
+UPDATE actors SET birthday = '' WHERE birthday IS NULL;
+UPDATE actors SET sex = '' WHERE sex IS NULL;
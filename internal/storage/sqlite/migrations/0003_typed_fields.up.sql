@@ -0,0 +1,58 @@
+-- Пустая строка раньше означала, что значение неизвестно, теперь это NULL.
+UPDATE films SET release = NULL WHERE release = '';
+UPDATE actors SET birthday = NULL WHERE birthday = '';
+UPDATE actors SET sex = NULL WHERE sex = '';
+
+-- Оставшиеся неразбираемые значения прерывают миграцию со списком строк,
+-- чтобы их можно было исправить вручную, а не потерять. date() нормализует
+-- несуществующие даты вроде 2001-02-30, поэтому значение должно совпасть
+-- с результатом date() в точности.
+SELECT raise_error('films with release not in YYYY-MM-DD format: ' || group_concat(id || ' (' || quote(release) || ')', ', '))
+FROM (SELECT id, release FROM films WHERE date(release) IS NOT release ORDER BY id)
+HAVING count(*) > 0;
+
+SELECT raise_error('actors with birthday not in YYYY-MM-DD format: ' || group_concat(id || ' (' || quote(birthday) || ')', ', '))
+FROM (SELECT id, birthday FROM actors WHERE date(birthday) IS NOT birthday ORDER BY id)
+HAVING count(*) > 0;
+
+SELECT raise_error('actors with sex other than male or female: ' || group_concat(id || ' (' || quote(sex) || ')', ', '))
+FROM (SELECT id, sex FROM actors WHERE sex NOT IN ('male', 'female') ORDER BY id)
+HAVING count(*) > 0;
+
+-- SQLite не добавляет CHECK в существующую таблицу, а пересоздание films
+-- удалило бы каскадом связи в film_actors, поэтому формат проверяют триггеры.
+CREATE TRIGGER films_release_insert BEFORE INSERT ON films
+WHEN date(NEW.release) IS NOT NEW.release
+BEGIN
+	SELECT RAISE(ABORT, 'films.release must be a date in YYYY-MM-DD format');
+END;
+
+CREATE TRIGGER films_release_update BEFORE UPDATE OF release ON films
+WHEN date(NEW.release) IS NOT NEW.release
+BEGIN
+	SELECT RAISE(ABORT, 'films.release must be a date in YYYY-MM-DD format');
+END;
+
+CREATE TRIGGER actors_birthday_insert BEFORE INSERT ON actors
+WHEN date(NEW.birthday) IS NOT NEW.birthday
+BEGIN
+	SELECT RAISE(ABORT, 'actors.birthday must be a date in YYYY-MM-DD format');
+END;
+
+CREATE TRIGGER actors_birthday_update BEFORE UPDATE OF birthday ON actors
+WHEN date(NEW.birthday) IS NOT NEW.birthday
+BEGIN
+	SELECT RAISE(ABORT, 'actors.birthday must be a date in YYYY-MM-DD format');
+END;
+
+CREATE TRIGGER actors_sex_insert BEFORE INSERT ON actors
+WHEN NEW.sex NOT IN ('male', 'female')
+BEGIN
+	SELECT RAISE(ABORT, 'actors.sex must be male or female');
+END;
+
+CREATE TRIGGER actors_sex_update BEFORE UPDATE OF sex ON actors
+WHEN NEW.sex NOT IN ('male', 'female')
+BEGIN
+	SELECT RAISE(ABORT, 'actors.sex must be male or female');
+END;
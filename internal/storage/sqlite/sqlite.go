@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"vk/internal/storage"
+	"vk/internal/storage/migrate"
+	"vk/internal/storage/sqlstore"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+func init() {
+	// unicode_lower переводит строку в нижний регистр по правилам Unicode,
+	// встроенная lower в SQLite меняет только ASCII и не подходит для кириллицы.
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			switch v := args[0].(type) {
+			case string:
+				return strings.ToLower(v), nil
+			case []byte:
+				return strings.ToLower(string(v)), nil
+			default:
+				return v, nil
+			}
+		})
+
+	// raise_error прерывает запрос с ошибкой msg. Миграции бросают через нее
+	// ошибки с перечнем строк: RAISE в SQLite принимает только литерал.
+	sqlite.MustRegisterScalarFunction("raise_error", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			return nil, fmt.Errorf("%v", args[0])
+		})
+}
+
+// dialect - особенности запросов SQLite для sqlstore.
+var dialect = sqlstore.Dialect{
+	Placeholder: storage.Question,
+	// release хранится текстом, date() разбирает его как дату и дает NULL
+	// для нераспознанных значений.
+	FilmSortKeys: map[string]sqlstore.SortKey{
+		storage.SortByName:    {Asc: "f.name", Desc: "f.name", Param: "?"},
+		storage.SortByRating:  {Asc: "COALESCE(f.rating, 2147483647)", Desc: "COALESCE(f.rating, -2147483648)", Param: "CAST(? AS INTEGER)"},
+		storage.SortByRelease: {Asc: "COALESCE(date(f.release), '9999-12-31')", Desc: "COALESCE(date(f.release), '')", Param: "?"},
+	},
+	// Встроенные LIKE и lower в SQLite понимают регистр только для ASCII,
+	// поэтому сравниваем через unicode_lower, см. init.
+	ILike: func(column string) string {
+		return "unicode_lower(" + column + ") LIKE unicode_lower(?)"
+	},
+	AnyOf: func(column string, ids []int64) (string, []any) {
+		args := make([]any, 0, len(ids))
+		for _, id := range ids {
+			args = append(args, id)
+		}
+
+		return column + " IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ") + ")", args
+	},
+	// Отдельная блокировка не нужна: транзакции открываются как
+	// BEGIN IMMEDIATE (см. dsn) и уже держат блокировку записи на всю базу.
+	LockRow:               "",
+	IsForeignKeyViolation: isForeignKeyViolation,
+}
+
+type Storage struct {
+	*sqlstore.Store
+	db       *sql.DB
+	migrator *migrate.Migrator
+}
+
+// New открывает файл базы. Схему создают миграции, см. Migrator.
+func New(storagePath string) (*Storage, error) {
+	const op = "storage.sqlite.New"
+
+	db, err := sql.Open("sqlite", dsn(storagePath))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Storage{Store: sqlstore.New(db, dialect), db: db, migrator: migrator}, nil
+}
+
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+func (s *Storage) Migrator() *migrate.Migrator {
+	return s.migrator
+}
+
+func newMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(db, fsys)
+}
+
+// dsn включает проверку внешних ключей, которая в SQLite по умолчанию
+// выключена, и ожидание блокировки вместо немедленной ошибки SQLITE_BUSY.
+// Транзакции открываются как BEGIN IMMEDIATE и сразу берут блокировку
+// записи: отложенная транзакция, которая сначала читает, а потом пишет,
+// при встречной записи получает SQLITE_BUSY без ожидания busy_timeout.
+func dsn(storagePath string) string {
+	sep := "?"
+	if strings.Contains(storagePath, "?") {
+		sep = "&"
+	}
+
+	return storagePath + sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+}
+
+// isForeignKeyViolation сообщает, что запрос нарушил внешний ключ.
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY
+}
@@ -0,0 +1,149 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"vk/internal/models"
+	"vk/internal/storage"
+	"vk/internal/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return migrated(t)
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	s := open(t)
+
+	storagetest.RunMigrations(t, s, func(ctx context.Context, name string) (bool, error) {
+		var n int
+		err := s.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&n)
+		return n > 0, err
+	})
+}
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+	s := migrated(t)
+
+	insert := func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO actors (name) VALUES ('Киану Ривз')")
+		return err
+	}
+
+	errFailed := errors.New("failed")
+	err := storage.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := insert(tx); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("WithTx() error = %v, want %v", err, errFailed)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("WithTx() did not pass the panic on")
+			}
+		}()
+		storage.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+			if err := insert(tx); err != nil {
+				return err
+			}
+			panic("failed")
+		})
+	}()
+
+	if n := countRows(t, s, "actors"); n != 0 {
+		t.Fatalf("actors after rolled back transactions = %d, want 0", n)
+	}
+
+	if err := storage.WithTx(ctx, s.db, insert); err != nil {
+		t.Fatalf("WithTx() error = %v", err)
+	}
+	if n := countRows(t, s, "actors"); n != 1 {
+		t.Fatalf("actors after committed transaction = %d, want 1", n)
+	}
+}
+
+// TestAddFilmRollback проверяет, что ошибка после добавления фильма
+// не оставляет ни фильма, ни части его состава.
+func TestAddFilmRollback(t *testing.T) {
+	ctx := context.Background()
+	s := migrated(t)
+
+	for _, name := range []string{"Киану Ривз", "Кэрри-Энн Мосс"} {
+		if _, err := s.AddActor(ctx, models.Actor{Name: name}); err != nil {
+			t.Fatalf("AddActor(%s) error = %v", name, err)
+		}
+	}
+
+	// Триггер отклоняет связь со вторым актером, когда фильм и связь
+	// с первым уже добавлены.
+	_, err := s.db.ExecContext(ctx, `CREATE TRIGGER fail_second_link BEFORE INSERT ON film_actors
+		WHEN NEW.actor_id = 2 BEGIN SELECT RAISE(ABORT, 'link failed'); END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	film := models.CreateFilm{
+		Film:   models.Film{Name: "Матрица"},
+		Actors: []models.ActorRef{{ID: 1}, {New: &models.Actor{Name: "Лоренс Фишберн"}}, {ID: 2}},
+	}
+	if _, err := s.AddFilm(ctx, film); err == nil {
+		t.Fatal("AddFilm() error = nil, want the trigger error")
+	}
+
+	for _, table := range []string{"films", "film_actors"} {
+		if n := countRows(t, s, table); n != 0 {
+			t.Errorf("%s after failed AddFilm() = %d rows, want 0", table, n)
+		}
+	}
+	if n := countRows(t, s, "actors"); n != 2 {
+		t.Errorf("actors after failed AddFilm() = %d rows, want 2", n)
+	}
+}
+
+// open открывает хранилище в новом файле, который удаляется после теста.
+func open(t *testing.T) *Storage {
+	t.Helper()
+
+	s, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { s.db.Close() })
+
+	return s
+}
+
+// migrated открывает хранилище и применяет все миграции.
+func migrated(t *testing.T) *Storage {
+	t.Helper()
+
+	s := open(t)
+	if _, err := s.Migrator().Up(context.Background()); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	return s
+}
+
+func countRows(t *testing.T, s *Storage, table string) int {
+	t.Helper()
+
+	var n int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
@@ -0,0 +1,797 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"vk/internal/models"
+	"vk/internal/storage"
+)
+
+const filmColumns = "f.id, f.name, f.description, f.rating, f.release"
+
+func (s *Store) GetAllFilms(ctx context.Context, q storage.FilmQuery) (models.Page[models.Film], error) {
+	const op = "storage.sqlstore.GetAllFilms"
+	q = q.Normalize()
+
+	key, ok := s.dialect.FilmSortKeys[q.SortBy]
+	if !ok {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: unknown sort field %q", op, q.SortBy)
+	}
+	expr := key.Asc
+	if q.Order == storage.OrderDesc {
+		expr = key.Desc
+	}
+
+	cursor, err := storage.DecodeCursor(q.Cursor, q.SortBy, q.Order)
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	from := " FROM films f"
+	var args []any
+	if q.ActorID != 0 {
+		from += " JOIN film_actors fa ON fa.film_id = f.id AND fa.actor_id = ?"
+		args = append(args, q.ActorID)
+	}
+
+	var total int
+	err = s.db.QueryRowContext(ctx, s.rebind("SELECT COUNT(*)"+from), args...).Scan(&total)
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	direction, cmp := keysetOrder(q.Order == storage.OrderDesc, cursor.Before)
+	query := "SELECT " + filmColumns + ", CAST(" + expr + " AS TEXT)" + from
+	if q.Cursor != "" {
+		query += fmt.Sprintf(" WHERE (%s, f.id) %s (%s, ?)", expr, cmp, key.Param)
+		args = append(args, cursor.Key, cursor.ID)
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, f.id %s LIMIT %d", expr, direction, direction, q.Limit+1)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var films []storage.Keyed[models.Film]
+	for rows.Next() {
+		var film storage.Keyed[models.Film]
+		err := rows.Scan(&film.Item.ID, &film.Item.Name, &film.Item.Description, &film.Item.Rating, &film.Item.Release, &film.Key)
+		if err != nil {
+			return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+		}
+		film.ID = film.Item.ID
+		films = append(films, film)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return storage.MakePage(films, q.PageQuery, cursor, total), nil
+}
+
+// keysetOrder возвращает направление ORDER BY и оператор сравнения
+// с курсором. Страница перед курсором читается в обратном порядке.
+func keysetOrder(desc, before bool) (direction, cmp string) {
+	if desc != before {
+		return "DESC", "<"
+	}
+
+	return "ASC", ">"
+}
+
+func (s *Store) SearchFilms(ctx context.Context, fragment string, q storage.PageQuery) (models.Page[models.Film], error) {
+	const op = "storage.sqlstore.SearchFilms"
+	q = q.Normalize()
+
+	cursor, err := storage.DecodeCursor(q.Cursor, "", "")
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// EXISTS вместо JOIN, чтобы фильм с несколькими подходящими актерами не дублировался
+	from := ` FROM films f
+	WHERE (` + s.dialect.ILike("f.name") + ` ESCAPE '\'
+	OR EXISTS (
+		SELECT 1 FROM film_actors fa JOIN actors a ON a.id = fa.actor_id
+		WHERE fa.film_id = f.id AND ` + s.dialect.ILike("a.name") + ` ESCAPE '\'
+	))`
+	pattern := storage.ContainsPattern(fragment)
+	args := []any{pattern, pattern}
+
+	var total int
+	err = s.db.QueryRowContext(ctx, s.rebind("SELECT COUNT(*)"+from), args...).Scan(&total)
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	direction, cmp := keysetOrder(false, cursor.Before)
+	query := "SELECT " + filmColumns + from
+	if q.Cursor != "" {
+		query += " AND f.id " + cmp + " ?"
+		args = append(args, cursor.ID)
+	}
+	query += fmt.Sprintf(" ORDER BY f.id %s LIMIT %d", direction, q.Limit+1)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var films []storage.Keyed[models.Film]
+	for rows.Next() {
+		var film storage.Keyed[models.Film]
+		err := rows.Scan(&film.Item.ID, &film.Item.Name, &film.Item.Description, &film.Item.Rating, &film.Item.Release)
+		if err != nil {
+			return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+		}
+		film.ID = film.Item.ID
+		films = append(films, film)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return storage.MakePage(films, q, cursor, total), nil
+}
+
+func (s *Store) GetAllActors(ctx context.Context, q storage.PageQuery) (models.Page[models.Actor], error) {
+	const op = "storage.sqlstore.GetAllActors"
+	q = q.Normalize()
+
+	cursor, err := storage.DecodeCursor(q.Cursor, "", "")
+	if err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var total int
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM actors").Scan(&total)
+	if err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	direction, cmp := keysetOrder(false, cursor.Before)
+	query := "SELECT a.id, a.name, a.sex, a.birthday FROM actors a"
+	var args []any
+	if q.Cursor != "" {
+		query += " WHERE a.id " + cmp + " ?"
+		args = append(args, cursor.ID)
+	}
+	query += fmt.Sprintf(" ORDER BY a.id %s LIMIT %d", direction, q.Limit+1)
+
+	actors, err := s.queryActors(ctx, s.db, query, args...)
+	if err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return storage.MakePage(actors, q, cursor, total), nil
+}
+
+// queryActors читает актеров из запроса, который выбирает id, name, sex, birthday.
+func (s *Store) queryActors(ctx context.Context, db storage.Querier, query string, args ...any) ([]storage.Keyed[models.Actor], error) {
+	rows, err := db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actors []storage.Keyed[models.Actor]
+	for rows.Next() {
+		var actor storage.Keyed[models.Actor]
+		err := rows.Scan(&actor.Item.ID, &actor.Item.Name, &actor.Item.Sex, &actor.Item.Birthday)
+		if err != nil {
+			return nil, err
+		}
+		actor.ID = actor.Item.ID
+		actors = append(actors, actor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return actors, nil
+}
+
+func (s *Store) AddFilm(ctx context.Context, film models.CreateFilm) (models.FilmWithActors, error) {
+	const op = "storage.sqlstore.AddFilm"
+
+	// Фильм и все связи с актерами добавляются в одной транзакции,
+	// поэтому ошибка на любом шаге не оставит фильм с неполным составом.
+	var created models.FilmWithActors
+	err := storage.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+		actorIDs, err := s.resolveActors(ctx, tx, film.Actors)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Добавление записи о фильме в таблицу films
+		query := "INSERT INTO films (name, description, rating, release) VALUES (?, ?, ?, ?) RETURNING id"
+		var filmID int64
+		err = tx.QueryRowContext(ctx, s.rebind(query), film.Name, film.Description, film.Rating, film.Release).Scan(&filmID)
+		if err != nil {
+			return fmt.Errorf("failed to add film: %w", err)
+		}
+
+		// Связывание актеров с фильмом в таблице film_actors
+		query = s.rebind("INSERT INTO film_actors (film_id, actor_id) VALUES (?, ?)")
+		for _, actorID := range actorIDs {
+			_, err = tx.ExecContext(ctx, query, filmID, actorID)
+			if s.dialect.IsForeignKeyViolation(err) {
+				// Актера удалили после resolveActors.
+				return storage.MissingActors([]int64{actorID}, nil)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to link actor with film: %w", err)
+			}
+		}
+
+		created.Film, err = s.findFilm(ctx, tx, filmID)
+		if err != nil {
+			return err
+		}
+		created.Actors, err = s.filmCast(ctx, tx, filmID)
+		return err
+	})
+	if err != nil {
+		return models.FilmWithActors{}, err
+	}
+
+	return created, nil
+}
+
+// resolveActors находит или создает актеров из состава нового фильма
+// и возвращает их id без повторов в порядке ссылок.
+func (s *Store) resolveActors(ctx context.Context, tx *sql.Tx, refs []models.ActorRef) ([]int64, error) {
+	var byID []int64
+	for _, ref := range refs {
+		if ref.New == nil && ref.Name == "" {
+			byID = append(byID, ref.ID)
+		}
+	}
+	if err := s.checkActorIDs(ctx, tx, byID); err != nil {
+		return nil, err
+	}
+
+	actorIDs := make([]int64, 0, len(refs))
+	seen := make(map[int64]bool, len(refs))
+	for _, ref := range refs {
+		actorID := ref.ID
+		var err error
+		switch {
+		case ref.New != nil:
+			actorID, err = s.insertActor(ctx, tx, *ref.New)
+		case ref.Name != "":
+			actorID, err = s.actorIDByName(ctx, tx, ref.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[actorID] {
+			seen[actorID] = true
+			actorIDs = append(actorIDs, actorID)
+		}
+	}
+
+	return actorIDs, nil
+}
+
+// actorIDByName возвращает id единственного актера с таким именем. Если их
+// несколько, ошибка перечисляет их в Details, чтобы клиент выбрал по ID.
+func (s *Store) actorIDByName(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	query := "SELECT id, name, sex, birthday FROM actors WHERE name = ? ORDER BY id"
+	candidates, err := s.queryActors(ctx, tx, query, name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch len(candidates) {
+	case 0:
+		return 0, storage.Invalid("actor %q not found", name)
+	case 1:
+		return candidates[0].ID, nil
+	}
+
+	actors := make([]models.Actor, 0, len(candidates))
+	for _, candidate := range candidates {
+		actors = append(actors, candidate.Item)
+	}
+
+	return 0, &storage.Error{
+		Kind:    storage.ErrValidation,
+		Message: fmt.Sprintf("actor name %q matches %d actors, use an actor ID", name, len(actors)),
+		Details: actors,
+	}
+}
+
+func (s *Store) insertActor(ctx context.Context, db storage.Querier, actor models.Actor) (int64, error) {
+	query := "INSERT INTO actors (name, sex, birthday) VALUES (?, ?, ?) RETURNING id"
+
+	var id int64
+	err := db.QueryRowContext(ctx, s.rebind(query), actor.Name, actor.Sex, actor.Birthday).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to add actor: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s *Store) DeleteFilm(ctx context.Context, id int64) error {
+	const op = "storage.sqlstore.DeleteFilm"
+	query := "DELETE FROM films WHERE id = ?"
+
+	res, err := s.db.ExecContext(ctx, s.rebind(query), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := storage.RequireAffected(res, storage.NotFound("film %d not found", id)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Store) FindFilm(ctx context.Context, id int64) (models.Film, error) {
+	const op = "storage.sqlstore.FindFilm"
+
+	film, err := s.findFilm(ctx, s.db, id)
+	if err != nil {
+		return models.Film{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return film, nil
+}
+
+func (s *Store) findFilm(ctx context.Context, db storage.Querier, id int64) (models.Film, error) {
+	query := "SELECT " + filmColumns + " FROM films f WHERE f.id = ?"
+
+	var film models.Film
+	err := db.QueryRowContext(ctx, s.rebind(query), id).Scan(&film.ID, &film.Name, &film.Description, &film.Rating, &film.Release)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Film{}, storage.NotFound("film %d not found", id)
+	}
+	if err != nil {
+		return models.Film{}, err
+	}
+
+	return film, nil
+}
+
+func (s *Store) UpdateFilm(ctx context.Context, id int64, patch models.FilmPatch) (models.Film, error) {
+	const op = "storage.sqlstore.UpdateFilm"
+
+	update := storage.NewUpdate("films", s.dialect.Placeholder)
+	if patch.Name.Set {
+		update.Set("name", patch.Name.Value)
+	}
+	if patch.Description.Set {
+		update.Set("description", patch.Description.Value)
+	}
+	if patch.Rating.Set {
+		update.Set("rating", patch.Rating.Value)
+	}
+	if patch.Release.Set {
+		update.Set("release", patch.Release.Value)
+	}
+
+	var updated models.Film
+	err := storage.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+		if !update.Empty() {
+			query, args := update.Query(id)
+			res, err := tx.ExecContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			if err := storage.RequireAffected(res, storage.NotFound("film %d not found", id)); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		updated, err = s.findFilm(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return models.Film{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+func (s *Store) FindActor(ctx context.Context, id int64) (models.Actor, error) {
+	const op = "storage.sqlstore.FindActor"
+
+	actor, err := s.findActor(ctx, s.db, id)
+	if err != nil {
+		return models.Actor{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return actor, nil
+}
+
+func (s *Store) findActor(ctx context.Context, db storage.Querier, id int64) (models.Actor, error) {
+	query := "SELECT id, name, sex, birthday FROM actors WHERE id = ?"
+
+	var actor models.Actor
+	err := db.QueryRowContext(ctx, s.rebind(query), id).Scan(&actor.ID, &actor.Name, &actor.Sex, &actor.Birthday)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Actor{}, storage.NotFound("actor %d not found", id)
+	}
+	if err != nil {
+		return models.Actor{}, err
+	}
+
+	return actor, nil
+}
+
+func (s *Store) AddActor(ctx context.Context, actor models.Actor) (models.Actor, error) {
+	const op = "storage.sqlstore.AddActor"
+
+	id, err := s.insertActor(ctx, s.db, actor)
+	if err != nil {
+		return models.Actor{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	created, err := s.findActor(ctx, s.db, id)
+	if err != nil {
+		return models.Actor{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return created, nil
+}
+
+func (s *Store) DeleteActor(ctx context.Context, id int64, cascade bool) error {
+	const op = "storage.sqlstore.DeleteActor"
+
+	err := storage.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+		films, err := s.actorFilms(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(films) > 0 {
+			if !cascade {
+				return &storage.Error{
+					Kind:    storage.ErrConflict,
+					Message: fmt.Sprintf("actor %d is in the cast of %d films", id, len(films)),
+					Details: films,
+				}
+			}
+
+			_, err := tx.ExecContext(ctx, s.rebind("DELETE FROM film_actors WHERE actor_id = ?"), id)
+			if err != nil {
+				return err
+			}
+		}
+
+		res, err := tx.ExecContext(ctx, s.rebind("DELETE FROM actors WHERE id = ?"), id)
+		if s.dialect.IsForeignKeyViolation(err) {
+			// Актера добавили в фильм после проверки выше.
+			return storage.Conflict("actor %d is in the cast of some films", id)
+		}
+		if err != nil {
+			return err
+		}
+
+		return storage.RequireAffected(res, storage.NotFound("actor %d not found", id))
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// actorFilms возвращает фильмы, в составе которых есть актер.
+func (s *Store) actorFilms(ctx context.Context, tx *sql.Tx, actorID int64) ([]models.Film, error) {
+	query := "SELECT " + filmColumns + ` FROM films f
+	JOIN film_actors fa ON fa.film_id = f.id
+	WHERE fa.actor_id = ?
+	ORDER BY f.id`
+
+	rows, err := tx.QueryContext(ctx, s.rebind(query), actorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var films []models.Film
+	for rows.Next() {
+		var film models.Film
+		err := rows.Scan(&film.ID, &film.Name, &film.Description, &film.Rating, &film.Release)
+		if err != nil {
+			return nil, err
+		}
+		films = append(films, film)
+	}
+
+	return films, rows.Err()
+}
+
+func (s *Store) UpdateActor(ctx context.Context, id int64, patch models.ActorPatch) (models.Actor, error) {
+	const op = "storage.sqlstore.UpdateActor"
+
+	update := storage.NewUpdate("actors", s.dialect.Placeholder)
+	if patch.Name.Set {
+		update.Set("name", patch.Name.Value)
+	}
+	if patch.Sex.Set {
+		update.Set("sex", patch.Sex.Value)
+	}
+	if patch.Birthday.Set {
+		update.Set("birthday", patch.Birthday.Value)
+	}
+
+	var updated models.Actor
+	err := storage.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+		if !update.Empty() {
+			query, args := update.Query(id)
+			res, err := tx.ExecContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			if err := storage.RequireAffected(res, storage.NotFound("actor %d not found", id)); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		updated, err = s.findActor(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return models.Actor{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return updated, nil
+}
+
+func (s *Store) GetActorsByFilmID(ctx context.Context, filmID int64, q storage.PageQuery) (models.Page[models.Actor], error) {
+	const op = "storage.sqlstore.GetActorsByFilmID"
+	q = q.Normalize()
+
+	cursor, err := storage.DecodeCursor(q.Cursor, "", "")
+	if err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.requireRow(ctx, "films", filmID, storage.NotFound("film %d not found", filmID)); err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var total int
+	err = s.db.QueryRowContext(ctx, s.rebind("SELECT COUNT(*) FROM film_actors WHERE film_id = ?"), filmID).Scan(&total)
+	if err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	direction, cmp := keysetOrder(false, cursor.Before)
+	query := "SELECT a.id, a.name, a.sex, a.birthday FROM actors a JOIN film_actors fa ON a.id = fa.actor_id WHERE fa.film_id = ?"
+	args := []any{filmID}
+	if q.Cursor != "" {
+		query += " AND a.id " + cmp + " ?"
+		args = append(args, cursor.ID)
+	}
+	query += fmt.Sprintf(" ORDER BY a.id %s LIMIT %d", direction, q.Limit+1)
+
+	actors, err := s.queryActors(ctx, s.db, query, args...)
+	if err != nil {
+		return models.Page[models.Actor]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return storage.MakePage(actors, q, cursor, total), nil
+}
+
+func (s *Store) GetFilmsByActorID(ctx context.Context, actorID int64, q storage.FilmQuery) (models.Page[models.Film], error) {
+	const op = "storage.sqlstore.GetFilmsByActorID"
+
+	if err := s.requireRow(ctx, "actors", actorID, storage.NotFound("actor %d not found", actorID)); err != nil {
+		return models.Page[models.Film]{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	q.ActorID = actorID
+	return s.GetAllFilms(ctx, q)
+}
+
+func (s *Store) GetFilmsByActorIDs(ctx context.Context, actorIDs []int64) (map[int64][]models.Film, error) {
+	const op = "storage.sqlstore.GetFilmsByActorIDs"
+
+	films := make(map[int64][]models.Film, len(actorIDs))
+	if len(actorIDs) == 0 {
+		return films, nil
+	}
+
+	// фильмы каждого актера идут по дате выхода, фильмы без даты - в конце
+	cond, args := s.dialect.AnyOf("fa.actor_id", actorIDs)
+	query := "SELECT fa.actor_id, " + filmColumns + ` FROM films f
+	JOIN film_actors fa ON fa.film_id = f.id
+	WHERE ` + cond + `
+	ORDER BY fa.actor_id, ` + s.dialect.FilmSortKeys[storage.SortByRelease].Asc + `, f.id`
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var actorID int64
+		var film models.Film
+		err := rows.Scan(&actorID, &film.ID, &film.Name, &film.Description, &film.Rating, &film.Release)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		films[actorID] = append(films[actorID], film)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return films, nil
+}
+
+// requireRow возвращает notFound, если в table нет строки с данным id.
+func (s *Store) requireRow(ctx context.Context, table string, id int64, notFound error) error {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, s.rebind("SELECT EXISTS (SELECT 1 FROM "+table+" WHERE id = ?)"), id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return notFound
+	}
+
+	return nil
+}
+
+func (s *Store) AddFilmActors(ctx context.Context, filmID int64, actorIDs []int64) ([]models.Actor, error) {
+	const op = "storage.sqlstore.AddFilmActors"
+
+	cast, err := s.changeCast(ctx, filmID, actorIDs, false)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cast, nil
+}
+
+func (s *Store) ReplaceFilmActors(ctx context.Context, filmID int64, actorIDs []int64) ([]models.Actor, error) {
+	const op = "storage.sqlstore.ReplaceFilmActors"
+
+	cast, err := s.changeCast(ctx, filmID, actorIDs, true)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cast, nil
+}
+
+// changeCast добавляет актеров в состав фильма, а с replace сначала
+// убирает из него всех остальных.
+func (s *Store) changeCast(ctx context.Context, filmID int64, actorIDs []int64, replace bool) ([]models.Actor, error) {
+	var cast []models.Actor
+	err := storage.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.lockFilm(ctx, tx, filmID); err != nil {
+			return err
+		}
+		if err := s.checkActorIDs(ctx, tx, actorIDs); err != nil {
+			return err
+		}
+
+		if replace {
+			_, err := tx.ExecContext(ctx, s.rebind("DELETE FROM film_actors WHERE film_id = ?"), filmID)
+			if err != nil {
+				return err
+			}
+		}
+
+		query := s.rebind("INSERT INTO film_actors (film_id, actor_id) VALUES (?, ?) ON CONFLICT DO NOTHING")
+		for _, actorID := range actorIDs {
+			_, err := tx.ExecContext(ctx, query, filmID, actorID)
+			if s.dialect.IsForeignKeyViolation(err) {
+				// Актера удалили после checkActorIDs.
+				return storage.MissingActors([]int64{actorID}, nil)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		cast, err = s.filmCast(ctx, tx, filmID)
+		return err
+	})
+
+	return cast, err
+}
+
+func (s *Store) RemoveFilmActor(ctx context.Context, filmID, actorID int64) ([]models.Actor, error) {
+	const op = "storage.sqlstore.RemoveFilmActor"
+
+	var cast []models.Actor
+	err := storage.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := s.lockFilm(ctx, tx, filmID); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, s.rebind("DELETE FROM film_actors WHERE film_id = ? AND actor_id = ?"), filmID, actorID)
+		if err != nil {
+			return err
+		}
+		err = storage.RequireAffected(res, storage.NotFound("actor %d is not in the cast of film %d", actorID, filmID))
+		if err != nil {
+			return err
+		}
+
+		cast, err = s.filmCast(ctx, tx, filmID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cast, nil
+}
+
+// lockFilm проверяет, что фильм есть, и блокирует его до конца транзакции,
+// чтобы одновременные изменения состава одного фильма шли по очереди.
+// Как именно блокируется строка, задает Dialect.LockRow.
+func (s *Store) lockFilm(ctx context.Context, tx *sql.Tx, filmID int64) error {
+	var id int64
+	err := tx.QueryRowContext(ctx, s.rebind("SELECT id FROM films WHERE id = ?"+s.dialect.LockRow), filmID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return storage.NotFound("film %d not found", filmID)
+	}
+
+	return err
+}
+
+// checkActorIDs проверяет, что все актеры есть. Ненайденные id
+// перечисляются в Details ошибки.
+func (s *Store) checkActorIDs(ctx context.Context, tx *sql.Tx, actorIDs []int64) error {
+	if len(actorIDs) == 0 {
+		return nil
+	}
+
+	cond, args := s.dialect.AnyOf("id", actorIDs)
+	rows, err := tx.QueryContext(ctx, s.rebind("SELECT id FROM actors WHERE "+cond), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := make(map[int64]bool, len(actorIDs))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return storage.MissingActors(actorIDs, found)
+}
+
+// filmCast возвращает весь состав фильма по возрастанию id актеров.
+func (s *Store) filmCast(ctx context.Context, db storage.Querier, filmID int64) ([]models.Actor, error) {
+	query := "SELECT a.id, a.name, a.sex, a.birthday FROM actors a JOIN film_actors fa ON a.id = fa.actor_id WHERE fa.film_id = ? ORDER BY a.id"
+	rows, err := s.queryActors(ctx, db, query, filmID)
+	if err != nil {
+		return nil, err
+	}
+
+	cast := make([]models.Actor, 0, len(rows))
+	for _, row := range rows {
+		cast = append(cast, row.Item)
+	}
+
+	return cast, nil
+}
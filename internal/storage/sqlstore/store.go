@@ -0,0 +1,67 @@
+// Package sqlstore реализует хранилище поверх database/sql. Запросы общие
+// для всех SQL-баз, а то, чем базы отличаются, описывает Dialect.
+package sqlstore
+
+import (
+	"database/sql"
+	"strings"
+
+	"vk/internal/storage"
+)
+
+// Dialect описывает особенности SQL-базы. Во всех фрагментах запросов
+// параметры записываются как ?, Store сам заменяет их на Placeholder.
+type Dialect struct {
+	// Placeholder нумерует параметры запросов.
+	Placeholder storage.Placeholder
+	// FilmSortKeys задает ключи сортировки фильмов для storage.SortBy*.
+	FilmSortKeys map[string]SortKey
+	// ILike возвращает условие "column совпадает с шаблоном ?" без учета
+	// регистра, шаблон строит storage.ContainsPattern.
+	ILike func(column string) string
+	// AnyOf возвращает условие "column равен одному из ids" и его параметры.
+	AnyOf func(column string, ids []int64) (string, []any)
+	// LockRow дописывается к SELECT, чтобы заблокировать выбранную строку
+	// до конца транзакции. Пустая строка - база блокирует иначе.
+	LockRow string
+	// IsForeignKeyViolation сообщает, что запрос нарушил внешний ключ.
+	IsForeignKeyViolation func(err error) bool
+}
+
+// SortKey - выражение, по которому сортируется список. NULL заменяется
+// значением, которое при выбранном направлении оказывается в конце, поэтому
+// страницы выбираются простым сравнением пар (key, id) с курсором.
+type SortKey struct {
+	Asc  string
+	Desc string
+	// Param - параметр с ключом из курсора, приведенный к типу ключа.
+	// Ключ приходит в курсоре текстом, см. GetAllFilms.
+	Param string
+}
+
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func New(db *sql.DB, dialect Dialect) *Store {
+	return &Store{db: db, dialect: dialect}
+}
+
+// rebind заменяет ? в запросе параметрами диалекта по порядку. Других
+// вопросительных знаков в запросах хранилища нет.
+func (s *Store) rebind(query string) string {
+	var b strings.Builder
+	for n := 1; ; n++ {
+		i := strings.IndexByte(query, '?')
+		if i < 0 {
+			break
+		}
+		b.WriteString(query[:i])
+		b.WriteString(s.dialect.Placeholder(n))
+		query = query[i+1:]
+	}
+	b.WriteString(query)
+
+	return b.String()
+}
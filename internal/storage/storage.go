@@ -1,72 +1,104 @@
-package postgres
+package storage
 
 import (
-	"database/sql"
-	"fmt"
+	"context"
 
-	_ "github.com/lib/pq"
+	"vk/internal/models"
 )
 
 const (
-	host     = "localhost"
-	port     = 5432
-	user     = "postgres"
-	password = "123"
-	dbname   = "vk"
+	SortByName    = "name"
+	SortByRating  = "rating"
+	SortByRelease = "release"
 )
 
-var Storage *sql.DB
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
 
-func New() (*sql.DB, error) {
-	const op = "storage.New"
-	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s "+
-		"password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
+// FilmQuery задает порядок и страницу выдачи GetAllFilms. Без SortBy
+// фильмы сортируются по рейтингу, без Order рейтинг идет по убыванию,
+// а название и дата выхода - по возрастанию.
+type FilmQuery struct {
+	SortBy string
+	Order  string
+	// ActorID, если задан, оставляет только фильмы с этим актером.
+	ActorID int64
+	PageQuery
+}
 
-	//db, err := sql.Open("postgres", storagePath)
-	db, err := sql.Open("postgres", psqlInfo)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+// Normalize подставляет значения по умолчанию.
+func (q FilmQuery) Normalize() FilmQuery {
+	if q.SortBy == "" {
+		q.SortBy = SortByRating
 	}
-	Storage = db
-
-	// Создаем таблицу для фильмов
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS films (
-		id SERIAL PRIMARY KEY,
-		name TEXT NOT NULL,
-		description TEXT,
-		rating INTEGER, 
-		release TEXT
-	);`)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+	if q.Order == "" {
+		q.Order = OrderAsc
+		if q.SortBy == SortByRating {
+			q.Order = OrderDesc
+		}
 	}
+	q.PageQuery = q.PageQuery.Normalize()
 
-	// Создаем таблицу для актеров
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS actors (
-		id SERIAL PRIMARY KEY,
-		name TEXT NOT NULL,
-		sex TEXT,
-		birthday TEXT
-	);`)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
+	return q
+}
 
-	// Создаем таблицу для связи фильмов с актерами
-	_, err = db.Exec(`
-	CREATE TABLE IF NOT EXISTS film_actors (
-		film_id INTEGER,
-		actor_id INTEGER,
-		PRIMARY KEY (film_id, actor_id),
-		FOREIGN KEY (film_id) REFERENCES films(id),
-		FOREIGN KEY (actor_id) REFERENCES actors(id)
-	);`)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
-	}
+// FilmStore хранит фильмы.
+type FilmStore interface {
+	GetAllFilms(ctx context.Context, q FilmQuery) (models.Page[models.Film], error)
+	// SearchFilms ищет фильмы, в названии которых или в имени кого-то из актеров
+	// встречается fragment, без учета регистра. Фильмы идут по возрастанию id.
+	SearchFilms(ctx context.Context, fragment string, q PageQuery) (models.Page[models.Film], error)
+	FindFilm(ctx context.Context, id int64) (models.Film, error)
+	// AddFilm добавляет фильм и возвращает его вместе с составом.
+	AddFilm(ctx context.Context, film models.CreateFilm) (models.FilmWithActors, error)
+	// UpdateFilm меняет поля, заданные в патче, и возвращает фильм после
+	// изменения. Пустой патч ничего не меняет.
+	UpdateFilm(ctx context.Context, id int64, patch models.FilmPatch) (models.Film, error)
+	// DeleteFilm удаляет фильм вместе со связями с его актерами.
+	DeleteFilm(ctx context.Context, id int64) error
+}
+
+// ActorStore хранит актеров.
+type ActorStore interface {
+	GetAllActors(ctx context.Context, q PageQuery) (models.Page[models.Actor], error)
+	FindActor(ctx context.Context, id int64) (models.Actor, error)
+	AddActor(ctx context.Context, actor models.Actor) (models.Actor, error)
+	// UpdateActor меняет поля, заданные в патче, и возвращает актера после
+	// изменения. Пустой патч ничего не меняет.
+	UpdateActor(ctx context.Context, id int64, patch models.ActorPatch) (models.Actor, error)
+	// DeleteActor удаляет актера. Если актер снимался в фильмах, без cascade
+	// возвращается ErrConflict со списком этих фильмов в Details, а с cascade
+	// актер сначала убирается из их состава.
+	DeleteActor(ctx context.Context, id int64, cascade bool) error
+}
+
+// FilmActorStore хранит связи фильмов с актерами.
+type FilmActorStore interface {
+	// GetActorsByFilmID возвращает страницу состава фильма по возрастанию id актеров.
+	GetActorsByFilmID(ctx context.Context, filmID int64, q PageQuery) (models.Page[models.Actor], error)
+	// GetFilmsByActorID возвращает страницу фильмов с участием актера
+	// в порядке, заданном q, как GetAllFilms.
+	GetFilmsByActorID(ctx context.Context, actorID int64, q FilmQuery) (models.Page[models.Film], error)
+	// GetFilmsByActorIDs одним запросом возвращает фильмы каждого из актеров
+	// по дате выхода. Актеров без фильмов в результате нет.
+	GetFilmsByActorIDs(ctx context.Context, actorIDs []int64) (map[int64][]models.Film, error)
+	// AddFilmActors добавляет актеров в состав фильма, уже входящие в него
+	// пропускаются. Возвращает состав после изменения.
+	AddFilmActors(ctx context.Context, filmID int64, actorIDs []int64) ([]models.Actor, error)
+	// ReplaceFilmActors заменяет весь состав фильма на actorIDs.
+	ReplaceFilmActors(ctx context.Context, filmID int64, actorIDs []int64) ([]models.Actor, error)
+	// RemoveFilmActor убирает актера из состава фильма.
+	RemoveFilmActor(ctx context.Context, filmID, actorID int64) ([]models.Actor, error)
+}
+
+// Storage объединяет все хранилища, которые нужны HTTP-слою.
+type Storage interface {
+	FilmStore
+	ActorStore
+	FilmActorStore
 
-	return db, nil
+	// Close освобождает соединения с базой.
+	Close() error
 }
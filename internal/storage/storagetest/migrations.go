@@ -0,0 +1,65 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"vk/internal/storage"
+	"vk/internal/storage/migrate"
+)
+
+// Migrated - хранилище, схему которого создают миграции.
+type Migrated interface {
+	storage.Storage
+	Migrator() *migrate.Migrator
+}
+
+// RunMigrations проверяет миграции s на пустой базе: Up создает схему,
+// с которой работает хранилище, а Down по одной версии откатывает ее
+// и удаляет таблицы. tableExists сообщает, есть ли таблица в базе.
+func RunMigrations(t *testing.T, s Migrated, tableExists func(ctx context.Context, name string) (bool, error)) {
+	ctx := context.Background()
+
+	m := s.Migrator()
+	if err := m.Check(ctx); !errors.Is(err, migrate.ErrSchemaOutdated) {
+		t.Fatalf("Check() before Up() error = %v, want ErrSchemaOutdated", err)
+	}
+
+	// Повторный Up после полного отката проверяет, что down-миграции
+	// возвращают базу в исходное состояние.
+	for round := 0; round < 2; round++ {
+		applied, err := m.Up(ctx)
+		if err != nil {
+			t.Fatalf("round %d: Up() error = %v", round, err)
+		}
+		if len(applied) != m.Latest() {
+			t.Fatalf("round %d: Up() applied %d migrations, want %d", round, len(applied), m.Latest())
+		}
+		if err := m.Check(ctx); err != nil {
+			t.Fatalf("round %d: Check() error = %v", round, err)
+		}
+
+		testFilmActors(t, s)
+
+		for version := m.Latest(); version > 0; version-- {
+			down, err := m.Down(ctx)
+			if err != nil {
+				t.Fatalf("round %d: Down() error = %v", round, err)
+			}
+			if down.Version != version {
+				t.Fatalf("round %d: Down() rolled back %d, want %d", round, down.Version, version)
+			}
+		}
+		if _, err := m.Down(ctx); !errors.Is(err, migrate.ErrNoMigrations) {
+			t.Fatalf("round %d: Down() on empty database error = %v, want ErrNoMigrations", round, err)
+		}
+
+		for _, table := range []string{"films", "actors", "film_actors"} {
+			exists, err := tableExists(ctx, table)
+			if err != nil || exists {
+				t.Fatalf("round %d: table %s left after Down(): %v, error %v", round, table, exists, err)
+			}
+		}
+	}
+}
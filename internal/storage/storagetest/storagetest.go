@@ -0,0 +1,623 @@
+// Package storagetest содержит общие проверки реализаций storage.Storage,
+// чтобы все хранилища вели себя одинаково. Хранилище подключает их
+// в своем _test.go через Run.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"vk/internal/models"
+	"vk/internal/storage"
+)
+
+// Run проверяет хранилище, которое создает newStorage. Каждая проверка
+// получает новое пустое хранилище.
+func Run(t *testing.T, newStorage func(t *testing.T) storage.Storage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s storage.Storage)
+	}{
+		{name: "Films", test: testFilms},
+		{name: "Actors", test: testActors},
+		{name: "FilmActors", test: testFilmActors},
+		{name: "Search", test: testSearch},
+		{name: "SortFilms", test: testSortFilms},
+		{name: "PageFilms", test: testPageFilms},
+		{name: "Errors", test: testErrors},
+		{name: "DeleteWithCast", test: testDeleteWithCast},
+		{name: "ChangeCast", test: testChangeCast},
+		{name: "Filmography", test: testFilmography},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+func testFilms(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for _, film := range []models.Film{
+		{Name: "Матрица", Description: "Красная таблетка", Rating: 9, Release: models.NewDate(1999, time.March, 31)},
+		{Name: "Джон Уик", Rating: 8},
+	} {
+		if _, err := s.AddFilm(ctx, models.CreateFilm{Film: film}); err != nil {
+			t.Fatalf("AddFilm(%s) error = %v", film.Name, err)
+		}
+	}
+
+	// id выдаются по порядку с 1, как SERIAL; по умолчанию список идет
+	// по убыванию рейтинга
+	page, err := s.GetAllFilms(ctx, storage.FilmQuery{})
+	if err != nil {
+		t.Fatalf("GetAllFilms() error = %v", err)
+	}
+	films := page.Items
+	if len(films) != 2 || films[0].ID != 1 || films[1].ID != 2 {
+		t.Fatalf("GetAllFilms() = %+v, want films 1 and 2", films)
+	}
+
+	// null очищает поле, незаданные поля не меняются
+	patch := models.FilmPatch{Rating: models.Of(10), Description: models.Field[string]{Set: true, Null: true}}
+	want := models.Film{ID: 1, Name: "Матрица", Rating: 10, Release: models.NewDate(1999, time.March, 31)}
+	if got, err := s.UpdateFilm(ctx, 1, patch); err != nil || got != want {
+		t.Fatalf("UpdateFilm() = %+v, %v, want %+v", got, err, want)
+	}
+	if got, err := s.UpdateFilm(ctx, 1, models.FilmPatch{}); err != nil || got != want {
+		t.Fatalf("UpdateFilm() with empty patch = %+v, %v, want %+v", got, err, want)
+	}
+	if got, err := s.FindFilm(ctx, 1); err != nil || got != want {
+		t.Fatalf("FindFilm() = %+v, %v, want %+v", got, err, want)
+	}
+
+	// PUT заменяет все поля, в том числе на пустые
+	want = models.Film{ID: 1, Name: "Матрица", Rating: 9}
+	if got, err := s.UpdateFilm(ctx, 1, models.Film{Name: "Матрица", Rating: 9}.Patch()); err != nil || got != want {
+		t.Fatalf("UpdateFilm() with full patch = %+v, %v, want %+v", got, err, want)
+	}
+
+	if err := s.DeleteFilm(ctx, 1); err != nil {
+		t.Fatalf("DeleteFilm() error = %v", err)
+	}
+	if _, err := s.FindFilm(ctx, 1); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("FindFilm() of deleted film error = %v, want %v", err, storage.ErrNotFound)
+	}
+	if page, err := s.GetAllFilms(ctx, storage.FilmQuery{}); err != nil || len(page.Items) != 1 || page.Items[0].ID != 2 {
+		t.Fatalf("GetAllFilms() after delete = %+v, %v, want film 2", page.Items, err)
+	}
+}
+
+func testActors(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for i, actor := range []models.Actor{
+		{Name: "Киану Ривз", Sex: models.SexMale, Birthday: models.NewDate(1964, time.September, 2)},
+		{Name: "Кэрри-Энн Мосс", Sex: models.SexFemale},
+	} {
+		created, err := s.AddActor(ctx, actor)
+		if err != nil {
+			t.Fatalf("AddActor(%s) error = %v", actor.Name, err)
+		}
+		actor.ID = int64(i + 1)
+		if created != actor {
+			t.Fatalf("AddActor() = %+v, want %+v", created, actor)
+		}
+	}
+
+	page, err := s.GetAllActors(ctx, storage.PageQuery{})
+	if err != nil {
+		t.Fatalf("GetAllActors() error = %v", err)
+	}
+	actors := page.Items
+	if len(actors) != 2 || actors[0].ID != 1 || actors[1].ID != 2 {
+		t.Fatalf("GetAllActors() = %+v, want actors 1 and 2", actors)
+	}
+
+	want := models.Actor{ID: 2, Name: "Кэрри-Энн Мосс", Sex: models.SexFemale, Birthday: models.NewDate(1967, time.August, 21)}
+	if got, err := s.UpdateActor(ctx, 2, models.ActorPatch{Birthday: models.Of(models.NewDate(1967, time.August, 21))}); err != nil || got != want {
+		t.Fatalf("UpdateActor() = %+v, %v, want %+v", got, err, want)
+	}
+	if got, err := s.FindActor(ctx, 2); err != nil || got != want {
+		t.Fatalf("FindActor() = %+v, %v, want %+v", got, err, want)
+	}
+
+	if err := s.DeleteActor(ctx, 2, false); err != nil {
+		t.Fatalf("DeleteActor() error = %v", err)
+	}
+	if _, err := s.FindActor(ctx, 2); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("FindActor() of deleted actor error = %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func testFilmActors(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for _, name := range []string{"Киану Ривз", "Кэрри-Энн Мосс", "Лоренс Фишберн"} {
+		if _, err := s.AddActor(ctx, models.Actor{Name: name}); err != nil {
+			t.Fatalf("AddActor(%s) error = %v", name, err)
+		}
+	}
+
+	film := models.CreateFilm{
+		Film:   models.Film{Name: "Матрица"},
+		Actors: []models.ActorRef{{Name: "Лоренс Фишберн"}, {Name: "Киану Ривз"}},
+	}
+	if _, err := s.AddFilm(ctx, film); err != nil {
+		t.Fatalf("AddFilm() error = %v", err)
+	}
+	films, err := s.GetAllFilms(ctx, storage.FilmQuery{})
+	if err != nil || len(films.Items) != 1 {
+		t.Fatalf("GetAllFilms() = %+v, %v, want 1 film", films.Items, err)
+	}
+
+	// состав идет по возрастанию id актеров, а не в порядке ссылок
+	actors, err := s.GetActorsByFilmID(ctx, films.Items[0].ID, storage.PageQuery{})
+	if err != nil {
+		t.Fatalf("GetActorsByFilmID() error = %v", err)
+	}
+	if names := actorNames(actors.Items); names != "Киану Ривз, Лоренс Фишберн" {
+		t.Fatalf("GetActorsByFilmID() = %s, want Киану Ривз, Лоренс Фишберн", names)
+	}
+
+	// ссылки по ID, по имени и новый актер, повтор пропускается
+	film = models.CreateFilm{
+		Film:   models.Film{Name: "Джон Уик"},
+		Actors: []models.ActorRef{{New: &models.Actor{Name: "Иэн Макшейн"}}, {ID: 1}, {Name: "Киану Ривз"}},
+	}
+	created, err := s.AddFilm(ctx, film)
+	if err != nil {
+		t.Fatalf("AddFilm() with actor IDs error = %v", err)
+	}
+	// AddFilm возвращает фильм с выданным id и составом, как его отдаст FindFilm
+	if created.ID != 2 || created.Name != "Джон Уик" || actorNames(created.Actors) != "Киану Ривз, Иэн Макшейн" {
+		t.Fatalf("AddFilm() = %+v, want film 2 with Киану Ривз, Иэн Макшейн", created)
+	}
+	if created.Actors[1].ID != 4 {
+		t.Fatalf("AddFilm() new actor ID = %q, want 4", created.Actors[1].ID)
+	}
+
+	if _, err := s.AddActor(ctx, models.Actor{Name: "Киану Ривз"}); err != nil {
+		t.Fatalf("AddActor() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		actors []models.ActorRef
+	}{
+		{name: "unknown name", actors: []models.ActorRef{{Name: "Хьюго Уивинг"}}},
+		{name: "ambiguous name", actors: []models.ActorRef{{Name: "Киану Ривз"}}},
+		// новый актер не создается, если другая ссылка не найдена
+		{name: "unknown ID", actors: []models.ActorRef{{New: &models.Actor{Name: "Хьюго Уивинг"}}, {ID: 42}}},
+	}
+
+	for _, tt := range tests {
+		film := models.CreateFilm{Film: models.Film{Name: "Матрица: Перезагрузка"}, Actors: tt.actors}
+		if _, err := s.AddFilm(ctx, film); !errors.Is(err, storage.ErrValidation) {
+			t.Errorf("AddFilm() with %s error = %v, want %v", tt.name, err, storage.ErrValidation)
+		}
+	}
+
+	films, err = s.GetAllFilms(ctx, storage.FilmQuery{})
+	if err != nil || films.Total != 2 {
+		t.Fatalf("GetAllFilms() after failed adds = %+v, %v, want 2 films", films.Items, err)
+	}
+	actors, err = s.GetAllActors(ctx, storage.PageQuery{})
+	if err != nil || actors.Total != 5 {
+		t.Fatalf("GetAllActors() after failed adds = %+v, %v, want 5 actors", actors.Items, err)
+	}
+}
+
+func testSearch(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for _, name := range []string{"Киану Ривз", "Кэрри-Энн Мосс"} {
+		if _, err := s.AddActor(ctx, models.Actor{Name: name}); err != nil {
+			t.Fatalf("AddActor(%s) error = %v", name, err)
+		}
+	}
+	for _, film := range []models.CreateFilm{
+		{Film: models.Film{Name: "Матрица"}, Actors: []models.ActorRef{{Name: "Киану Ривз"}, {Name: "Кэрри-Энн Мосс"}}},
+		{Film: models.Film{Name: "Джон Уик"}, Actors: []models.ActorRef{{Name: "Киану Ривз"}}},
+		{Film: models.Film{Name: "Девчата"}},
+		{Film: models.Film{Name: "100% любовь"}},
+	} {
+		if _, err := s.AddFilm(ctx, film); err != nil {
+			t.Fatalf("AddFilm(%s) error = %v", film.Name, err)
+		}
+	}
+
+	tests := []struct {
+		fragment string
+		want     string
+	}{
+		{fragment: "мАтРиЦа", want: "Матрица"},
+		// Матрица подходит и по названию, и по обоим актерам, но выдается один раз
+		{fragment: "Р", want: "Матрица, Джон Уик"},
+		{fragment: "КИАНУ", want: "Матрица, Джон Уик"},
+		{fragment: "%", want: "100% любовь"},
+		{fragment: "_", want: ""},
+		{fragment: "Бриллиантовая рука", want: ""},
+	}
+
+	for _, tt := range tests {
+		page, err := s.SearchFilms(ctx, tt.fragment, storage.PageQuery{})
+		if err != nil {
+			t.Fatalf("SearchFilms(%q) error = %v", tt.fragment, err)
+		}
+		if names := filmNames(page.Items); names != tt.want {
+			t.Errorf("SearchFilms(%q) = %q, want %q", tt.fragment, names, tt.want)
+		}
+	}
+
+	// Total и курсоры считаются по найденным фильмам без повторов
+	page, err := s.SearchFilms(ctx, "Р", storage.PageQuery{Limit: 1})
+	if err != nil || page.Total != 2 || filmNames(page.Items) != "Матрица" || page.NextCursor == "" {
+		t.Fatalf("SearchFilms() first page = %+v, %v", page, err)
+	}
+	page, err = s.SearchFilms(ctx, "Р", storage.PageQuery{Limit: 1, Cursor: page.NextCursor})
+	if err != nil || filmNames(page.Items) != "Джон Уик" || page.NextCursor != "" || page.PrevCursor == "" {
+		t.Fatalf("SearchFilms() second page = %+v, %v", page, err)
+	}
+}
+
+func testSortFilms(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for _, film := range []models.Film{
+		{Name: "Матрица", Rating: 9, Release: models.NewDate(1999, time.March, 31)},
+		{Name: "Джон Уик", Rating: 8, Release: models.NewDate(2014, time.October, 24)},
+		{Name: "Девчата", Rating: 8},
+		{Name: "Адмирал", Rating: 6, Release: models.NewDate(2008, time.October, 9)},
+	} {
+		if _, err := s.AddFilm(ctx, models.CreateFilm{Film: film}); err != nil {
+			t.Fatalf("AddFilm(%s) error = %v", film.Name, err)
+		}
+	}
+
+	// При равных значениях фильмы идут по id в том же направлении,
+	// фильм без даты выхода всегда в конце.
+	tests := []struct {
+		query storage.FilmQuery
+		want  string
+	}{
+		{query: storage.FilmQuery{}, want: "Матрица, Девчата, Джон Уик, Адмирал"},
+		{query: storage.FilmQuery{SortBy: storage.SortByRating, Order: storage.OrderAsc}, want: "Адмирал, Джон Уик, Девчата, Матрица"},
+		{query: storage.FilmQuery{SortBy: storage.SortByName}, want: "Адмирал, Девчата, Джон Уик, Матрица"},
+		{query: storage.FilmQuery{SortBy: storage.SortByName, Order: storage.OrderDesc}, want: "Матрица, Джон Уик, Девчата, Адмирал"},
+		{query: storage.FilmQuery{SortBy: storage.SortByRelease}, want: "Матрица, Адмирал, Джон Уик, Девчата"},
+		{query: storage.FilmQuery{SortBy: storage.SortByRelease, Order: storage.OrderDesc}, want: "Джон Уик, Адмирал, Матрица, Девчата"},
+	}
+
+	for _, tt := range tests {
+		page, err := s.GetAllFilms(ctx, tt.query)
+		if err != nil {
+			t.Fatalf("GetAllFilms(%+v) error = %v", tt.query, err)
+		}
+		if names := filmNames(page.Items); names != tt.want {
+			t.Errorf("GetAllFilms(%+v) = %q, want %q", tt.query, names, tt.want)
+		}
+	}
+
+	if _, err := s.GetAllFilms(ctx, storage.FilmQuery{SortBy: "id"}); err == nil {
+		t.Error("GetAllFilms() with unknown sort field error = nil")
+	}
+}
+
+func testPageFilms(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for _, film := range []models.Film{
+		{Name: "Матрица", Rating: 9},
+		{Name: "Джон Уик", Rating: 8},
+		{Name: "Девчата", Rating: 8},
+		{Name: "Адмирал", Rating: 6},
+		{Name: "Бумер", Rating: 7},
+	} {
+		if _, err := s.AddFilm(ctx, models.CreateFilm{Film: film}); err != nil {
+			t.Fatalf("AddFilm(%s) error = %v", film.Name, err)
+		}
+	}
+
+	// Страницы по два фильма. Джон Уик и Девчата с одинаковым рейтингом
+	// попадают на разные страницы, так что курсор должен учитывать id.
+	pages := []string{"Матрица, Девчата", "Джон Уик, Бумер", "Адмирал"}
+
+	var got []string
+	q := storage.FilmQuery{PageQuery: storage.PageQuery{Limit: 2}}
+	for i := range pages {
+		page, err := s.GetAllFilms(ctx, q)
+		if err != nil {
+			t.Fatalf("GetAllFilms() page %d error = %v", i+1, err)
+		}
+		if page.Total != 5 {
+			t.Errorf("GetAllFilms() page %d Total = %d, want 5", i+1, page.Total)
+		}
+		if (page.PrevCursor == "") != (i == 0) || (page.NextCursor == "") != (i == len(pages)-1) {
+			t.Errorf("GetAllFilms() page %d cursors = %q, %q", i+1, page.PrevCursor, page.NextCursor)
+		}
+		got = append(got, filmNames(page.Items))
+		q.Cursor = page.NextCursor
+	}
+	if strings.Join(got, "; ") != strings.Join(pages, "; ") {
+		t.Fatalf("GetAllFilms() forward = %q, want %q", got, pages)
+	}
+
+	// обратно от последней страницы по PrevCursor
+	page, err := s.GetAllFilms(ctx, storage.FilmQuery{PageQuery: storage.PageQuery{Limit: 2}})
+	if err != nil {
+		t.Fatalf("GetAllFilms() error = %v", err)
+	}
+	for page.NextCursor != "" {
+		if page, err = s.GetAllFilms(ctx, storage.FilmQuery{PageQuery: storage.PageQuery{Limit: 2, Cursor: page.NextCursor}}); err != nil {
+			t.Fatalf("GetAllFilms() error = %v", err)
+		}
+	}
+	for i := len(pages) - 2; i >= 0; i-- {
+		q := storage.FilmQuery{PageQuery: storage.PageQuery{Limit: 2, Cursor: page.PrevCursor}}
+		if page, err = s.GetAllFilms(ctx, q); err != nil {
+			t.Fatalf("GetAllFilms() back to page %d error = %v", i+1, err)
+		}
+		if names := filmNames(page.Items); names != pages[i] {
+			t.Errorf("GetAllFilms() back to page %d = %q, want %q", i+1, names, pages[i])
+		}
+	}
+	if page.PrevCursor != "" {
+		t.Errorf("GetAllFilms() first page PrevCursor = %q, want empty", page.PrevCursor)
+	}
+
+	// курсор выдан для другого порядка
+	q = storage.FilmQuery{SortBy: storage.SortByName, PageQuery: storage.PageQuery{Limit: 2, Cursor: page.NextCursor}}
+	if _, err := s.GetAllFilms(ctx, q); !errors.Is(err, storage.ErrInvalidCursor) {
+		t.Errorf("GetAllFilms() with foreign cursor error = %v, want %v", err, storage.ErrInvalidCursor)
+	}
+}
+
+func testErrors(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	if _, err := s.AddActor(ctx, models.Actor{Name: "Киану Ривз"}); err != nil {
+		t.Fatalf("AddActor() error = %v", err)
+	}
+	film := models.CreateFilm{Film: models.Film{Name: "Матрица"}, Actors: []models.ActorRef{{Name: "Киану Ривз"}}}
+	if _, err := s.AddFilm(ctx, film); err != nil {
+		t.Fatalf("AddFilm() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{name: "UpdateFilm missing", call: func() error {
+			_, err := s.UpdateFilm(ctx, 42, models.FilmPatch{Rating: models.Of(1)})
+			return err
+		}, want: storage.ErrNotFound},
+		{name: "DeleteFilm missing", call: func() error { return s.DeleteFilm(ctx, 42) }, want: storage.ErrNotFound},
+		{name: "UpdateActor missing", call: func() error {
+			_, err := s.UpdateActor(ctx, 42, models.ActorPatch{Name: models.Of("Никто")})
+			return err
+		}, want: storage.ErrNotFound},
+		{name: "DeleteActor missing", call: func() error { return s.DeleteActor(ctx, 42, true) }, want: storage.ErrNotFound},
+		{name: "DeleteActor in cast", call: func() error { return s.DeleteActor(ctx, 1, false) }, want: storage.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		if err := tt.call(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func testDeleteWithCast(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for _, name := range []string{"Киану Ривз", "Кэрри-Энн Мосс"} {
+		if _, err := s.AddActor(ctx, models.Actor{Name: name}); err != nil {
+			t.Fatalf("AddActor(%s) error = %v", name, err)
+		}
+	}
+	for _, film := range []models.CreateFilm{
+		{Film: models.Film{Name: "Матрица"}, Actors: []models.ActorRef{{Name: "Киану Ривз"}, {Name: "Кэрри-Энн Мосс"}}},
+		{Film: models.Film{Name: "Джон Уик"}, Actors: []models.ActorRef{{Name: "Киану Ривз"}}},
+	} {
+		if _, err := s.AddFilm(ctx, film); err != nil {
+			t.Fatalf("AddFilm(%s) error = %v", film.Name, err)
+		}
+	}
+
+	// без cascade актер остается, а в ошибке перечислены его фильмы
+	err := s.DeleteActor(ctx, 1, false)
+	var serr *storage.Error
+	if !errors.As(err, &serr) || !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("DeleteActor() error = %v, want %v", err, storage.ErrConflict)
+	}
+	films, ok := serr.Details.([]models.Film)
+	if !ok || filmNames(films) != "Матрица, Джон Уик" {
+		t.Fatalf("DeleteActor() Details = %+v, want Матрица, Джон Уик", serr.Details)
+	}
+	if _, err := s.FindActor(ctx, 1); err != nil {
+		t.Fatalf("FindActor() after refused delete error = %v", err)
+	}
+
+	if err := s.DeleteActor(ctx, 1, true); err != nil {
+		t.Fatalf("DeleteActor() with cascade error = %v", err)
+	}
+	cast, err := s.GetActorsByFilmID(ctx, 1, storage.PageQuery{})
+	if err != nil {
+		t.Fatalf("GetActorsByFilmID() error = %v", err)
+	}
+	if names := actorNames(cast.Items); names != "Кэрри-Энн Мосс" {
+		t.Fatalf("GetActorsByFilmID() after cascade = %s, want Кэрри-Энн Мосс", names)
+	}
+
+	// фильм удаляется вместе со связями, после чего актер ни с чем не связан
+	if err := s.DeleteFilm(ctx, 1); err != nil {
+		t.Fatalf("DeleteFilm() with cast error = %v", err)
+	}
+	if err := s.DeleteActor(ctx, 2, false); err != nil {
+		t.Fatalf("DeleteActor() after film delete error = %v", err)
+	}
+}
+
+func testChangeCast(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for _, name := range []string{"Киану Ривз", "Кэрри-Энн Мосс", "Лоренс Фишберн"} {
+		if _, err := s.AddActor(ctx, models.Actor{Name: name}); err != nil {
+			t.Fatalf("AddActor(%s) error = %v", name, err)
+		}
+	}
+	film := models.CreateFilm{Film: models.Film{Name: "Матрица"}, Actors: []models.ActorRef{{Name: "Киану Ривз"}}}
+	if _, err := s.AddFilm(ctx, film); err != nil {
+		t.Fatalf("AddFilm() error = %v", err)
+	}
+
+	// уже входящий в состав актер и повторы пропускаются
+	cast, err := s.AddFilmActors(ctx, 1, []int64{3, 1, 3})
+	if err != nil {
+		t.Fatalf("AddFilmActors() error = %v", err)
+	}
+	if names := actorNames(cast); names != "Киану Ривз, Лоренс Фишберн" {
+		t.Fatalf("AddFilmActors() = %s, want Киану Ривз, Лоренс Фишберн", names)
+	}
+
+	// с неизвестными актерами состав не меняется, а их id есть в Details
+	_, err = s.AddFilmActors(ctx, 1, []int64{2, 42, 43})
+	var serr *storage.Error
+	if !errors.As(err, &serr) || !errors.Is(err, storage.ErrValidation) {
+		t.Fatalf("AddFilmActors() with unknown actors error = %v, want %v", err, storage.ErrValidation)
+	}
+	if missing, ok := serr.Details.([]int64); !ok || !slices.Equal(missing, []int64{42, 43}) {
+		t.Fatalf("AddFilmActors() Details = %v, want [42 43]", serr.Details)
+	}
+	if _, err := s.ReplaceFilmActors(ctx, 1, []int64{2, 42}); !errors.Is(err, storage.ErrValidation) {
+		t.Fatalf("ReplaceFilmActors() with unknown actor error = %v, want %v", err, storage.ErrValidation)
+	}
+
+	cast, err = s.RemoveFilmActor(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("RemoveFilmActor() error = %v", err)
+	}
+	if names := actorNames(cast); names != "Лоренс Фишберн" {
+		t.Fatalf("RemoveFilmActor() = %s, want Лоренс Фишберн", names)
+	}
+	if _, err := s.RemoveFilmActor(ctx, 1, 2); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("RemoveFilmActor() of non-member error = %v, want %v", err, storage.ErrNotFound)
+	}
+
+	cast, err = s.ReplaceFilmActors(ctx, 1, []int64{2, 1})
+	if err != nil {
+		t.Fatalf("ReplaceFilmActors() error = %v", err)
+	}
+	if names := actorNames(cast); names != "Киану Ривз, Кэрри-Энн Мосс" {
+		t.Fatalf("ReplaceFilmActors() = %s, want Киану Ривз, Кэрри-Энн Мосс", names)
+	}
+
+	if cast, err = s.ReplaceFilmActors(ctx, 1, []int64{}); err != nil || len(cast) != 0 {
+		t.Fatalf("ReplaceFilmActors() with empty list = %+v, %v, want empty cast", cast, err)
+	}
+	page, err := s.GetActorsByFilmID(ctx, 1, storage.PageQuery{})
+	if err != nil || len(page.Items) != 0 {
+		t.Fatalf("GetActorsByFilmID() after replace = %+v, %v, want empty cast", page.Items, err)
+	}
+
+	if _, err := s.AddFilmActors(ctx, 42, []int64{1}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("AddFilmActors() of missing film error = %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func testFilmography(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	for _, name := range []string{"Киану Ривз", "Кэрри-Энн Мосс", "Хьюго Уивинг"} {
+		if _, err := s.AddActor(ctx, models.Actor{Name: name}); err != nil {
+			t.Fatalf("AddActor(%s) error = %v", name, err)
+		}
+	}
+	for _, film := range []models.CreateFilm{
+		{Film: models.Film{Name: "Матрица", Rating: 9, Release: models.NewDate(1999, time.March, 31)}, Actors: []models.ActorRef{{ID: 1}, {ID: 2}}},
+		{Film: models.Film{Name: "Джон Уик", Rating: 8, Release: models.NewDate(2014, time.October, 24)}, Actors: []models.ActorRef{{ID: 1}}},
+		{Film: models.Film{Name: "Константин", Rating: 7, Release: models.NewDate(2005, time.February, 8)}, Actors: []models.ActorRef{{ID: 1}}},
+		{Film: models.Film{Name: "Мементо", Rating: 8, Release: models.NewDate(2000, time.September, 5)}, Actors: []models.ActorRef{{ID: 2}}},
+	} {
+		if _, err := s.AddFilm(ctx, film); err != nil {
+			t.Fatalf("AddFilm(%s) error = %v", film.Name, err)
+		}
+	}
+
+	tests := []struct {
+		actorID int64
+		query   storage.FilmQuery
+		want    string
+	}{
+		{actorID: 1, query: storage.FilmQuery{}, want: "Матрица, Джон Уик, Константин"},
+		{actorID: 1, query: storage.FilmQuery{SortBy: storage.SortByName}, want: "Джон Уик, Константин, Матрица"},
+		{actorID: 2, query: storage.FilmQuery{}, want: "Матрица, Мементо"},
+	}
+
+	for _, tt := range tests {
+		page, err := s.GetFilmsByActorID(ctx, tt.actorID, tt.query)
+		if err != nil {
+			t.Fatalf("GetFilmsByActorID(%d, %+v) error = %v", tt.actorID, tt.query, err)
+		}
+		if names := filmNames(page.Items); names != tt.want {
+			t.Errorf("GetFilmsByActorID(%d, %+v) = %q, want %q", tt.actorID, tt.query, names, tt.want)
+		}
+	}
+
+	// страницы фильмографии считаются только по фильмам актера
+	q := storage.FilmQuery{PageQuery: storage.PageQuery{Limit: 2}}
+	page, err := s.GetFilmsByActorID(ctx, 1, q)
+	if err != nil || page.Total != 3 || filmNames(page.Items) != "Матрица, Джон Уик" || page.NextCursor == "" {
+		t.Fatalf("GetFilmsByActorID() first page = %+v, %v", page, err)
+	}
+	q.Cursor = page.NextCursor
+	page, err = s.GetFilmsByActorID(ctx, 1, q)
+	if err != nil || filmNames(page.Items) != "Константин" || page.NextCursor != "" {
+		t.Fatalf("GetFilmsByActorID() second page = %+v, %v", page, err)
+	}
+
+	if _, err := s.GetFilmsByActorID(ctx, 42, storage.FilmQuery{}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetFilmsByActorID() of missing actor error = %v, want %v", err, storage.ErrNotFound)
+	}
+
+	// у актеров без фильмов и несуществующих записей в ответе нет
+	byActor, err := s.GetFilmsByActorIDs(ctx, []int64{1, 2, 3, 42})
+	if err != nil {
+		t.Fatalf("GetFilmsByActorIDs() error = %v", err)
+	}
+	got := make(map[int64]string, len(byActor))
+	for id, films := range byActor {
+		got[id] = filmNames(films)
+	}
+	want := map[int64]string{1: "Матрица, Константин, Джон Уик", 2: "Матрица, Мементо"}
+	if !maps.Equal(got, want) {
+		t.Fatalf("GetFilmsByActorIDs() = %v, want %v", got, want)
+	}
+}
+
+// filmNames перечисляет названия фильмов через запятую.
+func filmNames(films []models.Film) string {
+	names := make([]string, 0, len(films))
+	for _, film := range films {
+		names = append(names, film.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// actorNames перечисляет имена актеров через запятую.
+func actorNames(actors []models.Actor) string {
+	names := make([]string, 0, len(actors))
+	for _, actor := range actors {
+		names = append(names, actor.Name)
+	}
+	return strings.Join(names, ", ")
+}
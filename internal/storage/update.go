@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Placeholder возвращает параметр запроса с номером n, начиная с 1.
+type Placeholder func(n int) string
+
+// Dollar - параметры PostgreSQL: $1, $2, ...
+func Dollar(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// Question - параметры SQLite: ?, ?, ...
+func Question(int) string {
+	return "?"
+}
+
+// Update собирает UPDATE только из заданных полей. Параметры нумеруются
+// в порядке добавления, поэтому пропущенные поля не сбивают нумерацию.
+type Update struct {
+	table       string
+	placeholder Placeholder
+	sets        []string
+	args        []any
+}
+
+func NewUpdate(table string, placeholder Placeholder) *Update {
+	return &Update{table: table, placeholder: placeholder}
+}
+
+// Set присваивает column значение value.
+func (u *Update) Set(column string, value any) {
+	u.args = append(u.args, value)
+	u.sets = append(u.sets, column+" = "+u.placeholder(len(u.args)))
+}
+
+// Empty сообщает, что не задано ни одного поля.
+func (u *Update) Empty() bool {
+	return len(u.sets) == 0
+}
+
+// Query возвращает запрос, обновляющий строку с данным id, и его параметры.
+func (u *Update) Query(id int64) (string, []any) {
+	args := append(u.args[:len(u.args):len(u.args)], id)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = %s",
+		u.table, strings.Join(u.sets, ", "), u.placeholder(len(args)))
+
+	return query, args
+}
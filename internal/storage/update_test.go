@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUpdateQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		placeholder Placeholder
+		build       func(u *Update)
+		wantQuery   string
+		wantArgs    []any
+	}{
+		{
+			name:        "dollar",
+			placeholder: Dollar,
+			build: func(u *Update) {
+				u.Set("name", "Matrix")
+				u.Set("release", "1999-03-31")
+			},
+			wantQuery: "UPDATE films SET name = $1, release = $2 WHERE id = $3",
+			wantArgs:  []any{"Matrix", "1999-03-31", int64(7)},
+		},
+		{
+			name:        "dollar single field",
+			placeholder: Dollar,
+			build: func(u *Update) {
+				u.Set("rating", 9)
+			},
+			wantQuery: "UPDATE films SET rating = $1 WHERE id = $2",
+			wantArgs:  []any{9, int64(7)},
+		},
+		{
+			name:        "question",
+			placeholder: Question,
+			build: func(u *Update) {
+				u.Set("name", "Matrix")
+				u.Set("rating", 9)
+			},
+			wantQuery: "UPDATE films SET name = ?, rating = ? WHERE id = ?",
+			wantArgs:  []any{"Matrix", 9, int64(7)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewUpdate("films", tt.placeholder)
+			if !u.Empty() {
+				t.Fatal("new Update is not Empty")
+			}
+			tt.build(u)
+			if u.Empty() {
+				t.Fatal("Update with fields is Empty")
+			}
+
+			query, args := u.Query(7)
+			if query != tt.wantQuery {
+				t.Errorf("query = %q, want %q", query, tt.wantQuery)
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestUpdateQueryKeepsArgs(t *testing.T) {
+	u := NewUpdate("actors", Dollar)
+	u.Set("name", "Keanu")
+
+	_, first := u.Query(1)
+	_, second := u.Query(2)
+	if first[1] != int64(1) || second[1] != int64(2) {
+		t.Errorf("Query(1) args = %v, Query(2) args = %v", first, second)
+	}
+}
@@ -0,0 +1,230 @@
+// Package validation проверяет входные данные фильмов и актеров
+// до того, как они попадут в хранилище.
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"vk/internal/models"
+)
+
+const (
+	NameMinLength        = 1
+	NameMaxLength        = 150
+	DescriptionMaxLength = 1000
+	RatingMin            = 0
+	RatingMax            = 10
+)
+
+// Violation - нарушение правила для одного поля, Field - имя поля в JSON.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors - все нарушения, найденные в одном объекте.
+type Errors []Violation
+
+func (e Errors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, v := range e {
+		parts = append(parts, v.Field+": "+v.Message)
+	}
+
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// errs копит нарушения и превращает их в error только если они есть.
+type errs struct {
+	list Errors
+}
+
+func (e *errs) add(field, format string, args ...any) {
+	e.list = append(e.list, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *errs) err() error {
+	if len(e.list) == 0 {
+		return nil
+	}
+
+	return e.list
+}
+
+// TypeError превращает ошибку encoding/json о значении не того типа
+// в нарушение для поля, в котором оно встретилось.
+func TypeError(err *json.UnmarshalTypeError) error {
+	field := err.Field
+	if field == "" {
+		field = "body"
+	}
+
+	var e errs
+	e.add(field, "must be of type %s", err.Type.Kind())
+
+	return e.err()
+}
+
+// CreateFilm проверяет новый фильм: название обязательно, остальные поля
+// проверяются, только если заполнены. Ссылаться на актеров по имени можно,
+// только если allowActorNames.
+func CreateFilm(film models.CreateFilm, allowActorNames bool) error {
+	var e errs
+	checkName(&e, "name", film.Name)
+	checkDescription(&e, film.Description)
+	checkRating(&e, film.Rating)
+	checkDate(&e, "release", film.Release)
+
+	ids := make(map[int64]bool, len(film.Actors))
+	for i, ref := range film.Actors {
+		field := fmt.Sprintf("actors[%d]", i)
+		switch {
+		case ref.New != nil:
+			checkName(&e, field+".name", ref.New.Name)
+			checkSex(&e, field+".sex", ref.New.Sex)
+			checkDate(&e, field+".birthday", ref.New.Birthday)
+		case ref.Name != "":
+			if !allowActorNames {
+				e.add(field, "actor names are not accepted, use an actor ID")
+			} else if strings.TrimSpace(ref.Name) == "" {
+				e.add(field, "must not be blank")
+			}
+		case ref.ID <= 0:
+			e.add(field, "must be a positive actor ID")
+		case ids[ref.ID]:
+			e.add(field, "duplicate actor ID %d", ref.ID)
+		}
+		if ref.ID > 0 {
+			ids[ref.ID] = true
+		}
+	}
+
+	return e.err()
+}
+
+// Film проверяет фильм, полностью заменяющий прежний: название обязательно.
+func Film(film models.Film) error {
+	var e errs
+	checkName(&e, "name", film.Name)
+	checkDescription(&e, film.Description)
+	checkRating(&e, film.Rating)
+	checkDate(&e, "release", film.Release)
+
+	return e.err()
+}
+
+// FilmPatch проверяет поля, которые есть в патче. Название и рейтинг
+// нельзя очистить через null.
+func FilmPatch(patch models.FilmPatch) error {
+	var e errs
+	if patch.Name.Set {
+		if patch.Name.Null {
+			e.add("name", "must not be null")
+		} else {
+			checkName(&e, "name", patch.Name.Value)
+		}
+	}
+	checkDescription(&e, patch.Description.Value)
+	if patch.Rating.Null {
+		e.add("rating", "must not be null")
+	} else {
+		checkRating(&e, patch.Rating.Value)
+	}
+	checkDate(&e, "release", patch.Release.Value)
+
+	return e.err()
+}
+
+// CreateActor проверяет нового актера или актера, полностью заменяющего
+// прежнего: имя обязательно.
+func CreateActor(actor models.Actor) error {
+	var e errs
+	checkName(&e, "name", actor.Name)
+	checkSex(&e, "sex", actor.Sex)
+	checkDate(&e, "birthday", actor.Birthday)
+
+	return e.err()
+}
+
+// ActorPatch проверяет поля, которые есть в патче. Имя нельзя очистить через null.
+func ActorPatch(patch models.ActorPatch) error {
+	var e errs
+	if patch.Name.Set {
+		if patch.Name.Null {
+			e.add("name", "must not be null")
+		} else {
+			checkName(&e, "name", patch.Name.Value)
+		}
+	}
+	checkSex(&e, "sex", patch.Sex.Value)
+	checkDate(&e, "birthday", patch.Birthday.Value)
+
+	return e.err()
+}
+
+// AddCast проверяет актеров, добавляемых в состав фильма: нужен хотя бы один.
+func AddCast(cast models.FilmCast) error {
+	var e errs
+	if len(cast.ActorIDs) == 0 {
+		e.add("actor_ids", "must not be empty")
+	}
+	checkActorIDs(&e, cast.ActorIDs)
+
+	return e.err()
+}
+
+// ReplaceCast проверяет новый состав фильма, пустой состав допустим.
+func ReplaceCast(cast models.FilmCast) error {
+	var e errs
+	checkActorIDs(&e, cast.ActorIDs)
+
+	return e.err()
+}
+
+func checkName(e *errs, field, name string) {
+	n := utf8.RuneCountInString(strings.TrimSpace(name))
+	if n < NameMinLength || utf8.RuneCountInString(name) > NameMaxLength {
+		e.add(field, "length must be from %d to %d characters", NameMinLength, NameMaxLength)
+	}
+}
+
+func checkDescription(e *errs, description string) {
+	if utf8.RuneCountInString(description) > DescriptionMaxLength {
+		e.add("description", "length must be at most %d characters", DescriptionMaxLength)
+	}
+}
+
+func checkRating(e *errs, rating int) {
+	if rating < RatingMin || rating > RatingMax {
+		e.add("rating", "must be from %d to %d", RatingMin, RatingMax)
+	}
+}
+
+func checkDate(e *errs, field string, date models.Date) {
+	if !date.Valid() {
+		e.add(field, "must be a date in YYYY-MM-DD format")
+	}
+}
+
+func checkSex(e *errs, field string, sex models.Sex) {
+	if !sex.Valid() {
+		e.add(field, "must be one of %s, %s", models.SexMale, models.SexFemale)
+	}
+}
+
+func checkActorIDs(e *errs, ids []int64) {
+	seen := make(map[int64]bool, len(ids))
+	for i, id := range ids {
+		field := fmt.Sprintf("actor_ids[%d]", i)
+		switch {
+		case id <= 0:
+			e.add(field, "must be a positive actor ID")
+		case seen[id]:
+			e.add(field, "duplicate actor ID %d", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,192 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"vk/internal/models"
+)
+
+// fields возвращает поля, для которых err содержит нарушения.
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	var violations Errors
+	if !errors.As(err, &violations) {
+		t.Fatalf("error %v is not validation.Errors", err)
+	}
+
+	names := make([]string, 0, len(violations))
+	for _, v := range violations {
+		names = append(names, v.Field)
+	}
+	return names
+}
+
+func invalidDate(t *testing.T) models.Date {
+	t.Helper()
+
+	var d models.Date
+	if err := json.Unmarshal([]byte(`"31.12.1999"`), &d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestCreateFilm(t *testing.T) {
+	film := func(name string, rating int) models.CreateFilm {
+		return models.CreateFilm{Film: models.Film{Name: name, Rating: rating}}
+	}
+	withActors := func(f models.CreateFilm, refs ...models.ActorRef) models.CreateFilm {
+		f.Actors = refs
+		return f
+	}
+
+	tests := []struct {
+		name            string
+		film            models.CreateFilm
+		allowActorNames bool
+		want            []string
+	}{
+		{name: "valid", film: film("Matrix", 9)},
+		{name: "blank name", film: film("   ", 9), want: []string{"name"}},
+		{name: "long name", film: film(strings.Repeat("я", NameMaxLength+1), 9), want: []string{"name"}},
+		{name: "max name in runes", film: film(strings.Repeat("я", NameMaxLength), 9)},
+		{name: "rating out of range", film: film("Matrix", RatingMax+1), want: []string{"rating"}},
+		{
+			name: "release",
+			film: models.CreateFilm{Film: models.Film{Name: "Matrix", Release: invalidDate(t)}},
+			want: []string{"release"},
+		},
+		{
+			name: "actor ids",
+			film: withActors(film("Matrix", 9), models.ActorRef{ID: 1}, models.ActorRef{ID: 1}, models.ActorRef{ID: -2}),
+			want: []string{"actors[1]", "actors[2]"},
+		},
+		{
+			name: "actor name not allowed",
+			film: withActors(film("Matrix", 9), models.ActorRef{Name: "Keanu"}),
+			want: []string{"actors[0]"},
+		},
+		{
+			name:            "actor name allowed",
+			film:            withActors(film("Matrix", 9), models.ActorRef{Name: "Keanu"}),
+			allowActorNames: true,
+		},
+		{
+			name: "new actor",
+			film: withActors(film("Matrix", 9), models.ActorRef{New: &models.Actor{Name: "", Sex: "other"}}),
+			want: []string{"actors[0].name", "actors[0].sex"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(t, CreateFilm(tt.film, tt.allowActorNames))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CreateFilm() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilmPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch models.FilmPatch
+		want  []string
+	}{
+		{name: "empty", patch: models.FilmPatch{}},
+		{name: "name", patch: models.FilmPatch{Name: models.Of("Matrix")}},
+		{name: "blank name", patch: models.FilmPatch{Name: models.Of(" ")}, want: []string{"name"}},
+		{name: "null name", patch: models.FilmPatch{Name: models.Field[string]{Set: true, Null: true}}, want: []string{"name"}},
+		{name: "null rating", patch: models.FilmPatch{Rating: models.Field[int]{Set: true, Null: true}}, want: []string{"rating"}},
+		{name: "null description", patch: models.FilmPatch{Description: models.Field[string]{Set: true, Null: true}}},
+		{name: "null release", patch: models.FilmPatch{Release: models.Field[models.Date]{Set: true, Null: true}}},
+		{name: "invalid release", patch: models.FilmPatch{Release: models.Of(invalidDate(t))}, want: []string{"release"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(t, FilmPatch(tt.patch))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FilmPatch() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateActor(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor models.Actor
+		want  []string
+	}{
+		{name: "valid", actor: models.Actor{Name: "Keanu", Sex: models.SexMale, Birthday: models.NewDate(1964, 9, 2)}},
+		{name: "no name", actor: models.Actor{}, want: []string{"name"}},
+		{name: "invalid", actor: models.Actor{Name: "Keanu", Sex: "other", Birthday: invalidDate(t)}, want: []string{"sex", "birthday"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(t, CreateActor(tt.actor))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CreateActor() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActorPatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch models.ActorPatch
+		want  []string
+	}{
+		{name: "empty", patch: models.ActorPatch{}},
+		{name: "null name", patch: models.ActorPatch{Name: models.Field[string]{Set: true, Null: true}}, want: []string{"name"}},
+		{name: "null sex", patch: models.ActorPatch{Sex: models.Field[models.Sex]{Set: true, Null: true}}},
+		{name: "invalid sex", patch: models.ActorPatch{Sex: models.Of(models.Sex("other"))}, want: []string{"sex"}},
+		{name: "invalid birthday", patch: models.ActorPatch{Birthday: models.Of(invalidDate(t))}, want: []string{"birthday"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fields(t, ActorPatch(tt.patch))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ActorPatch() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCast(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []int64
+		wantAdd     []string
+		wantReplace []string
+	}{
+		{name: "empty", ids: nil, wantAdd: []string{"actor_ids"}},
+		{name: "valid", ids: []int64{1, 2}},
+		{name: "invalid", ids: []int64{1, 0, 1}, wantAdd: []string{"actor_ids[1]", "actor_ids[2]"}, wantReplace: []string{"actor_ids[1]", "actor_ids[2]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cast := models.FilmCast{ActorIDs: tt.ids}
+			if got := fields(t, AddCast(cast)); !slices.Equal(got, tt.wantAdd) {
+				t.Errorf("AddCast() fields = %v, want %v", got, tt.wantAdd)
+			}
+			if got := fields(t, ReplaceCast(cast)); !slices.Equal(got, tt.wantReplace) {
+				t.Errorf("ReplaceCast() fields = %v, want %v", got, tt.wantReplace)
+			}
+		})
+	}
+}